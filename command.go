@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrianpk/rida/internal/cfg"
+	"github.com/adrianpk/rida/internal/repo/pg"
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+const usage = `usage:
+  rida [flags]                   run the server
+  rida [flags] import <file>     upsert scooters from a .csv or .geojson file
+  rida [flags] export <file|->   write the fleet to a .csv or .geojson file (- for CSV on stdout)`
+
+// runCommand runs a one-off fleet command against the configured database
+// instead of starting the server.
+func runCommand(config *cfg.Config, db *pg.DB, repo *pg.TelemetryRepo, args []string) error {
+	if len(args) != 2 {
+		return errors.New(usage)
+	}
+
+	ctx := telemetry.WithOperator(context.Background())
+
+	// Commands only need the schema, demo data is never seeded.
+	err := db.Setup(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = repo.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	service := telemetry.NewService(repo, telemetry.WithLowBatteryThreshold(config.LowBatteryThreshold))
+
+	switch args[0] {
+	case "import":
+		return importFleet(ctx, service, args[1])
+	case "export":
+		return exportFleet(ctx, service, args[1])
+	default:
+		return errors.New(usage)
+	}
+}
+
+func importFleet(ctx context.Context, service telemetry.Service, path string) error {
+	format, err := fileFormat(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	res, err := service.ImportScooters(ctx, format, f)
+	for _, re := range res.Errors {
+		log.Printf("row %d %s: %s", re.Row, re.ID, re.Error)
+	}
+
+	log.Printf("imported %s: %d created, %d updated, %d failed", path, res.Created, res.Updated, res.Failed)
+
+	if err != nil {
+		return err
+	}
+
+	if res.Failed > 0 {
+		return fmt.Errorf("%d rows failed", res.Failed)
+	}
+
+	return nil
+}
+
+func exportFleet(ctx context.Context, service telemetry.Service, path string) error {
+	if path == "-" {
+		return service.ExportScooters(ctx, telemetry.FormatCSV, os.Stdout)
+	}
+
+	format, err := fileFormat(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = service.ExportScooters(ctx, format, f)
+	return errors.Join(err, f.Close())
+}
+
+// fileFormat picks the fleet format from the file extension.
+func fileFormat(path string) (telemetry.FleetFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return telemetry.FormatCSV, nil
+	case ".geojson", ".json":
+		return telemetry.FormatGeoJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %s", telemetry.ErrUnsupportedFormat, path)
+	}
+}
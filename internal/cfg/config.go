@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type ClientsConfig struct {
@@ -22,17 +23,33 @@ type PgConfig struct {
 }
 
 type Config struct {
-	APIKey   string
-	HTTPPort string
-	Pg       PgConfig
-	Clients  ClientsConfig
+	APIKey              string
+	OperatorAPIKey      string
+	HTTPPort            string
+	GRPCPort            string
+	LowBatteryThreshold int
+	ReservationTTL      time.Duration
+	DedupWindow         time.Duration
+	MaxClockSkew        time.Duration
+	TariffsFile         string
+	Dev                 bool
+	Pg                  PgConfig
+	Clients             ClientsConfig
 }
 
 func Load() *Config {
 	apiKey := flag.String("api-key", getenv("RIDA_API_KEY", "demo-api-key"), "API key for simulated clients")
+	operatorAPIKey := flag.String("operator-api-key", getenv("RIDA_OPERATOR_API_KEY", ""), "API key for fleet operators; operator endpoints are disabled when empty")
 	ottawaQty := flag.Int("ottawa-clients", getenvInt("RIDA_OTTAWA_CLIENTS", 1), "Number of Ottawa clients")
 	montrealQty := flag.Int("montreal-clients", getenvInt("RIDA_MONTREAL_CLIENTS", 2), "Number of Montreal clients")
 	httpPort := flag.String("http-port", getenv("RIDA_HTTP_PORT", ":8080"), "HTTP server port (e.g. :8080)")
+	grpcPort := flag.String("grpc-port", getenv("RIDA_GRPC_PORT", ":9090"), "gRPC server port (e.g. :9090)")
+	reservationTTL := flag.Duration("reservation-ttl", getenvDuration("RIDA_RESERVATION_TTL", 5*time.Minute), "How long a reservation holds a scooter")
+	dedupWindow := flag.Duration("dedup-window", getenvDuration("RIDA_DEDUP_WINDOW", 24*time.Hour), "How long retried events get their original result back (0 disables deduplication)")
+	maxClockSkew := flag.Duration("max-clock-skew", getenvDuration("RIDA_MAX_CLOCK_SKEW", time.Minute), "How far ahead of the server clock event times may be")
+	tariffsFile := flag.String("tariffs", getenv("RIDA_TARIFFS_FILE", "deployment/tariffs.json"), "JSON file with trip tariffs (empty disables pricing)")
+	dev := flag.Bool("dev", getenvBool("RIDA_DEV", false), "Development mode: check API responses against the OpenAPI spec")
+	lowBattery := flag.Int("low-battery", getenvInt("RIDA_LOW_BATTERY_THRESHOLD", 15), "Battery percentage below which scooters are not rentable")
 	pgHost := flag.String("pg-host", getenv("RIDA_PG_HOST", "localhost"), "Postgres host")
 	pgPort := flag.String("pg-port", getenv("RIDA_PG_PORT", "5432"), "Postgres port")
 	pgUser := flag.String("pg-user", getenv("RIDA_PG_USER", "postgres"), "Postgres user")
@@ -42,8 +59,16 @@ func Load() *Config {
 	flag.Parse()
 
 	return &Config{
-		APIKey:   *apiKey,
-		HTTPPort: *httpPort,
+		APIKey:              *apiKey,
+		OperatorAPIKey:      *operatorAPIKey,
+		HTTPPort:            *httpPort,
+		GRPCPort:            *grpcPort,
+		LowBatteryThreshold: *lowBattery,
+		ReservationTTL:      *reservationTTL,
+		DedupWindow:         *dedupWindow,
+		MaxClockSkew:        *maxClockSkew,
+		TariffsFile:         *tariffsFile,
+		Dev:                 *dev,
 		Clients: ClientsConfig{
 			OttawaQty:   *ottawaQty,
 			MontrealQty: *montrealQty,
@@ -84,3 +109,25 @@ func getenvInt(key string, fallback int) int {
 
 	return fallback
 }
+
+func getenvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return fallback
+}
+
+func getenvDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+
+	return fallback
+}
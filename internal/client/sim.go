@@ -21,6 +21,7 @@ const (
 	APIHost      = "localhost"
 	APIPort      = 8080
 	ScootersPath = "/api/v1/scooters"
+	NearbyPath   = ScootersPath + "/nearby"
 
 	FindScootersRetryDelay = 1 * time.Second
 	NoScootersRestDelay    = 2 * time.Second
@@ -33,6 +34,8 @@ const (
 	RestDurationJitter     = 4 // seconds
 	LatJitter              = 0.01
 	LngJitter              = 0.01
+	SearchRadius           = 400 // meters
+	SearchLimit            = 5
 )
 
 type Sim struct {
@@ -55,17 +58,17 @@ func NewSim(apiKey string, lat, lng float64, tag string) *Sim {
 	}
 }
 
-func (c *Sim) FindScooters(ctx context.Context) ([]telemetry.Scooter, error) {
-	area := c.BoundingBox(400)
+// FindScooters returns the free scooters around the rider, nearest first.
+func (c *Sim) FindScooters(ctx context.Context) ([]telemetry.NearbyScooter, error) {
 	status := telemetry.StatusFree
 
-	baseURL := fmt.Sprintf("http://%s:%d%s", APIHost, APIPort, ScootersPath)
+	baseURL := fmt.Sprintf("http://%s:%d%s", APIHost, APIPort, NearbyPath)
 
 	params := url.Values{}
-	params.Set("minLat", fmt.Sprintf("%f", area.MinLat))
-	params.Set("maxLat", fmt.Sprintf("%f", area.MaxLat))
-	params.Set("minLng", fmt.Sprintf("%f", area.MinLng))
-	params.Set("maxLng", fmt.Sprintf("%f", area.MaxLng))
+	params.Set("lat", fmt.Sprintf("%f", c.Lat))
+	params.Set("lng", fmt.Sprintf("%f", c.Lng))
+	params.Set("radius", fmt.Sprintf("%d", SearchRadius))
+	params.Set("limit", fmt.Sprintf("%d", SearchLimit))
 	params.Set("status", string(status))
 
 	fullURL := baseURL + "?" + params.Encode()
@@ -85,7 +88,7 @@ func (c *Sim) FindScooters(ctx context.Context) ([]telemetry.Scooter, error) {
 		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
 	}
 
-	var scooters []telemetry.Scooter
+	var scooters []telemetry.NearbyScooter
 	if err := json.NewDecoder(resp.Body).Decode(&scooters); err != nil {
 		return nil, err
 	}
@@ -93,6 +96,28 @@ func (c *Sim) FindScooters(ctx context.Context) ([]telemetry.Scooter, error) {
 	return scooters, nil
 }
 
+// Reserve holds the scooter while the rider walks to it.
+func (c *Sim) Reserve(ctx context.Context, scooterID uuid.UUID) error {
+	url := fmt.Sprintf("http://%s:%d%s/%s/reservations", APIHost, APIPort, ScootersPath, scooterID)
+
+	req, err := c.newReq(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("reservation failed: %s", resp.Status)
+	}
+
+	return nil
+}
+
 func (c *Sim) StartRide(ctx context.Context, scooterID uuid.UUID) error {
 	event := telemetry.Event{
 		ScooterID: scooterID,
@@ -130,6 +155,7 @@ func (c *Sim) UpdateLocation(ctx context.Context, scooterID uuid.UUID, lat, lng
 // sendEvent posts an event to the backend /api/v1/events endpoint.
 func (c *Sim) sendEvent(ctx context.Context, event telemetry.Event) error {
 	url := fmt.Sprintf("http://%s:%d/api/v1/events", APIHost, APIPort)
+	event.OccurredAt = time.Now()
 
 	body, err := json.Marshal(event)
 	if err != nil {
@@ -201,6 +227,13 @@ func (c *Sim) Run(ctx context.Context) error {
 			continue
 		}
 
+		if err := c.Reserve(ctx, scooterID); err != nil {
+			c.logf("error reserving scooter: %v", err)
+			continue
+		}
+
+		c.logf("reserve scooter")
+
 		time.Sleep(PreRideDelay)
 
 		if err := c.StartRide(ctx, scooterID); err != nil {
@@ -270,12 +303,19 @@ func (c *Sim) logf(format string, args ...interface{}) {
 	log.Printf("[%s] %s", c.TagID(), fmt.Sprintf(format, args...))
 }
 
-// pickNearest returns the first scooter ID from the slice, assuming the slice
-// is ordered by distance (nearest first).
-func pickNearest(scooters []telemetry.Scooter) (uuid.UUID, bool) {
+// pickNearest returns the ID of the scooter with the shortest distance. The
+// API already sorts results, but the pick does not depend on it.
+func pickNearest(scooters []telemetry.NearbyScooter) (uuid.UUID, bool) {
 	if len(scooters) == 0 {
 		return uuid.Nil, false
 	}
 
-	return scooters[0].ID, true
+	nearest := scooters[0]
+	for _, s := range scooters[1:] {
+		if s.Distance < nearest.Distance {
+			nearest = s
+		}
+	}
+
+	return nearest.ID, nearest.ID != uuid.Nil
 }
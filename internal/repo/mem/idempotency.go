@@ -0,0 +1,59 @@
+package mem
+
+import (
+	"context"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+// processedKey scopes idempotency keys by client.
+type processedKey struct {
+	clientID string
+	key      string
+}
+
+func (r *TelemetryRepo) GetProcessedEvent(ctx context.Context, clientID, key string, since time.Time) (telemetry.ProcessedEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.processed[processedKey{clientID: clientID, key: key}]
+	if !ok || p.CreatedAt.Before(since) {
+		return telemetry.ProcessedEvent{}, telemetry.ErrNotFound
+	}
+
+	if p.Result.Trip != nil {
+		trip := *p.Result.Trip
+		p.Result.Trip = &trip
+	}
+
+	return p, nil
+}
+
+func (r *TelemetryRepo) SaveProcessedEvent(ctx context.Context, p telemetry.ProcessedEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p.Result.Trip != nil {
+		trip := *p.Result.Trip
+		p.Result.Trip = &trip
+	}
+
+	r.processed[processedKey{clientID: p.ClientID, key: p.Key}] = p
+	return nil
+}
+
+func (r *TelemetryRepo) DeleteProcessedEvents(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int
+	for k, p := range r.processed {
+		if p.CreatedAt.Before(before) {
+			delete(r.processed, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,49 @@
+package mem
+
+import (
+	"context"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func (r *TelemetryRepo) CreateReservation(ctx context.Context, res telemetry.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reservations[res.ScooterID] = res
+	return nil
+}
+
+func (r *TelemetryRepo) GetReservation(ctx context.Context, scooterID uuid.UUID) (telemetry.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res, ok := r.reservations[scooterID]
+	if !ok {
+		return telemetry.Reservation{}, telemetry.ErrNotFound
+	}
+
+	return res, nil
+}
+
+func (r *TelemetryRepo) DeleteReservation(ctx context.Context, scooterID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reservations, scooterID)
+	return nil
+}
+
+func (r *TelemetryRepo) FindExpiredReservations(ctx context.Context, at time.Time) ([]telemetry.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Reservation
+	for _, res := range r.reservations {
+		if res.Expired(at) {
+			result = append(result, res)
+		}
+	}
+
+	return result, nil
+}
@@ -58,12 +58,13 @@ func (r *TelemetryRepo) SeedCity(count int, area telemetry.Area) {
 		}
 
 		s := telemetry.Scooter{
-			Status: status,
-			Lat:    area.MinLat + rand.Float64()*(area.MaxLat-area.MinLat),
-			Lng:    area.MinLng + rand.Float64()*(area.MaxLng-area.MinLng),
+			Status:  status,
+			Lat:     area.MinLat + rand.Float64()*(area.MaxLat-area.MinLat),
+			Lng:     area.MinLng + rand.Float64()*(area.MaxLng-area.MinLng),
+			Battery: 30 + rand.Intn(71), // above the low battery threshold
 		}
 
-		s.GenID()
+		s.GenCreateVals()
 
 		r.scooters[s.ID] = s
 	}
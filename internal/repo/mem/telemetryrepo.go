@@ -2,7 +2,7 @@ package mem
 
 import (
 	"context"
-	"errors"
+	"sort"
 	"sync"
 
 	"github.com/adrianpk/rida/internal/telemetry"
@@ -14,15 +14,21 @@ import (
 // This implementation is intended for development and testing purposes. In a production
 // environment, a robust persistence layer (such as a SQL or NoSQL database) should be used.
 //
-// Transactions: The repository interface does not expose specific methods for transaction
-// management. Each concrete implementation should handle transactions as appropriate,
-// ideally obtaining them from the context. This keeps the interface simple and decoupled
+// Transactions: InTx hands the transaction over in the context, each concrete
+// implementation obtains it from there. This keeps the interface simple and decoupled
 // from infrastructure details, making it easier to integrate with different database engines
-// and persistence patterns.
+// and persistence patterns. The in-memory repo has no rollback: it serves a single process,
+// where the service already applies the changes of a scooter one at a time.
 type TelemetryRepo struct {
-	mu       sync.RWMutex
-	scooters map[uuid.UUID]telemetry.Scooter
-	events   []telemetry.Event
+	mu           sync.RWMutex
+	scooters     map[uuid.UUID]telemetry.Scooter
+	events       []telemetry.Event
+	eventIDs     map[uuid.UUID]struct{}
+	processed    map[processedKey]telemetry.ProcessedEvent
+	trips        map[uuid.UUID]telemetry.Trip
+	reservations map[uuid.UUID]telemetry.Reservation // keyed by scooter ID
+	zones        map[uuid.UUID]telemetry.Zone
+	violations   []telemetry.Violation
 }
 
 func NewTelemetryRepo(initial ...map[uuid.UUID]telemetry.Scooter) *TelemetryRepo {
@@ -36,7 +42,12 @@ func NewTelemetryRepo(initial ...map[uuid.UUID]telemetry.Scooter) *TelemetryRepo
 	}
 
 	repo := &TelemetryRepo{
-		scooters: scooters,
+		scooters:     scooters,
+		eventIDs:     make(map[uuid.UUID]struct{}),
+		processed:    make(map[processedKey]telemetry.ProcessedEvent),
+		trips:        make(map[uuid.UUID]telemetry.Trip),
+		reservations: make(map[uuid.UUID]telemetry.Reservation),
+		zones:        make(map[uuid.UUID]telemetry.Zone),
 	}
 
 	return repo
@@ -48,42 +59,233 @@ func (r *TelemetryRepo) GetScooter(ctx context.Context, id uuid.UUID) (telemetry
 
 	s, ok := r.scooters[id]
 	if !ok {
-		return telemetry.Scooter{}, errors.New("not found")
+		return telemetry.Scooter{}, telemetry.ErrNotFound
 	}
 
 	return s, nil
 }
 
+// InTx runs fn, the writes it makes are not rolled back on failure.
+func (r *TelemetryRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// LockScooter returns the scooter; scooters are locked by the service.
+func (r *TelemetryRepo) LockScooter(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error) {
+	return r.GetScooter(ctx, id)
+}
+
+func (r *TelemetryRepo) CreateScooter(ctx context.Context, s telemetry.Scooter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.scooters[s.ID]; ok {
+		return telemetry.ErrAlreadyExists
+	}
+
+	r.scooters[s.ID] = s
+	return nil
+}
+
+func (r *TelemetryRepo) DeleteScooter(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.scooters[id]
+	if !ok {
+		return telemetry.ErrNotFound
+	}
+
+	s.Status = telemetry.StatusDecommissioned
+	s.AuditUpdate()
+	s.Version++
+	r.scooters[id] = s
+	return nil
+}
+
 func (r *TelemetryRepo) UpdateScooter(ctx context.Context, s telemetry.Scooter) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	stored, ok := r.scooters[s.ID]
+	if !ok {
+		return telemetry.ErrNotFound
+	}
+
+	if stored.Version != s.Version {
+		return telemetry.ErrVersionConflict
+	}
+
+	s.Version++
 	r.scooters[s.ID] = s
 	return nil
 }
 
-func (r *TelemetryRepo) FindScootersInArea(ctx context.Context, area telemetry.Area, status telemetry.Status) ([]telemetry.Scooter, error) {
+func (r *TelemetryRepo) FindScootersInArea(ctx context.Context, qry telemetry.Query) ([]telemetry.Scooter, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	after := ""
+	if qry.After != uuid.Nil {
+		after = qry.After.String()
+	}
+
 	var result []telemetry.Scooter
 	for _, s := range r.scooters {
-		if s.Status == status &&
-			s.Lat >= area.MinLat && s.Lat <= area.MaxLat &&
-			s.Lng >= area.MinLng && s.Lng <= area.MaxLng {
+		if matchStatus(qry.Status, s.Status) &&
+			s.Battery >= qry.MinBattery &&
+			qry.Area.Contains(s.Location()) &&
+			s.ID.String() > after {
 			result = append(result, s)
 		}
 	}
 
+	sortByID(result)
+
+	if qry.Limit > 0 && len(result) > qry.Limit {
+		result = result[:qry.Limit]
+	}
+
+	return result, nil
+}
+
+func (r *TelemetryRepo) FindNearbyScooters(ctx context.Context, qry telemetry.NearbyQuery) ([]telemetry.NearbyScooter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.NearbyScooter
+	for _, s := range r.scooters {
+		if !qry.Match(s) {
+			continue
+		}
+
+		d := telemetry.Distance(qry.Center, s.Location())
+		if d <= qry.Radius {
+			result = append(result, telemetry.NearbyScooter{Scooter: s, Distance: d})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Distance != result[j].Distance {
+			return result[i].Distance < result[j].Distance
+		}
+		return result[i].ID.String() < result[j].ID.String()
+	})
+
+	if len(result) > qry.Limit {
+		result = result[:qry.Limit]
+	}
+
 	return result, nil
 }
 
+func (r *TelemetryRepo) CountScootersByCell(ctx context.Context, qry telemetry.GridQuery) ([]telemetry.CellCount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[telemetry.CellCount]int)
+	for _, s := range r.scooters {
+		if !matchStatus(qry.Status, s.Status) || !qry.Area.Contains(s.Location()) {
+			continue
+		}
+
+		row, col := qry.Cell(s.Location())
+		counts[telemetry.CellCount{Row: row, Col: col, Status: s.Status}]++
+	}
+
+	result := make([]telemetry.CellCount, 0, len(counts))
+	for c, n := range counts {
+		c.Count = n
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Row != b.Row {
+			return a.Row < b.Row
+		}
+		if a.Col != b.Col {
+			return a.Col < b.Col
+		}
+		return a.Status < b.Status
+	})
+
+	return result, nil
+}
+
+func (r *TelemetryRepo) ListScooters(ctx context.Context) ([]telemetry.Scooter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]telemetry.Scooter, 0, len(r.scooters))
+	for _, s := range r.scooters {
+		result = append(result, s)
+	}
+
+	sortByID(result)
+
+	return result, nil
+}
+
+// sortByID orders scooters by ID the way the pg repo does, comparing the
+// canonical string form.
+func sortByID(scooters []telemetry.Scooter) {
+	sort.Slice(scooters, func(i, j int) bool {
+		return scooters[i].ID.String() < scooters[j].ID.String()
+	})
+}
+
+// matchStatus treats an empty query status as any status but decommissioned.
+func matchStatus(want, got telemetry.Status) bool {
+	if want == "" {
+		return got != telemetry.StatusDecommissioned
+	}
+
+	return got == want
+}
+
 func (r *TelemetryRepo) StoreEvent(ctx context.Context, e telemetry.Event) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	if _, ok := r.eventIDs[e.ID]; ok {
+		return telemetry.ErrAlreadyExists
+	}
+
+	r.eventIDs[e.ID] = struct{}{}
 	r.events = append(r.events, e)
 	return nil
 }
 
+// FindEvents returns the events matching the query in the order they
+// occurred. Events that occurred at the same time keep the order they were
+// received in.
+func (r *TelemetryRepo) FindEvents(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Event
+	for _, e := range r.events {
+		if qry.Match(e) {
+			result = append(result, e)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].OccurredAt.Before(result[j].OccurredAt)
+	})
+
+	if qry.Limit > 0 && len(result) > qry.Limit {
+		if qry.Latest {
+			result = result[len(result)-qry.Limit:]
+		} else {
+			result = result[:qry.Limit]
+		}
+	}
+
+	return result, nil
+}
+
 // Scooters returns a copy of the scooters map for black-box testing.
 func (r *TelemetryRepo) Scooters() map[uuid.UUID]telemetry.Scooter {
 	r.mu.RLock()
@@ -2,7 +2,10 @@ package mem_test
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/adrianpk/rida/internal/repo/mem"
 	"github.com/adrianpk/rida/internal/telemetry"
@@ -18,7 +21,7 @@ func TestGetScooterByID(t *testing.T) {
 		Lng:    17.0385,
 	}
 
-	err := repo.UpdateScooter(context.Background(), testScooter)
+	err := repo.CreateScooter(context.Background(), testScooter)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -81,17 +84,16 @@ func TestUpdateScooter(t *testing.T) {
 			},
 		},
 		{
-			name:    "add new scooter",
+			name:    "missing scooter",
 			initial: map[uuid.UUID]telemetry.Scooter{},
 			update: telemetry.Scooter{
 				ID:     uuid.New(),
 				Status: telemetry.StatusFree,
 			},
-			wantErr: false,
+			wantErr: true,
 			checkFunc: func(repo *mem.TelemetryRepo, s telemetry.Scooter) bool {
-				scooters := repo.Scooters()
-				stored, ok := scooters[s.ID]
-				return ok && stored.Status == s.Status
+				_, ok := repo.Scooters()[s.ID]
+				return !ok
 			},
 		},
 	}
@@ -103,6 +105,12 @@ func TestUpdateScooter(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
 			}
+			if tt.wantErr && !errors.Is(err, telemetry.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if tt.wantErr && !tt.checkFunc(repo, tt.update) {
+				t.Errorf("missing scooter was inserted")
+			}
 			if !tt.wantErr && !tt.checkFunc(repo, tt.update) {
 				t.Errorf("scooter not updated as expected")
 			}
@@ -110,6 +118,32 @@ func TestUpdateScooter(t *testing.T) {
 	}
 }
 
+func TestUpdateScooterVersion(t *testing.T) {
+	id := uuid.New()
+	repo := mem.NewTelemetryRepo(map[uuid.UUID]telemetry.Scooter{
+		id: {ID: id, Status: telemetry.StatusFree, Version: 1},
+	})
+
+	read, _ := repo.GetScooter(context.Background(), id)
+
+	read.Battery = 50
+	err := repo.UpdateScooter(context.Background(), read)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored, _ := repo.GetScooter(context.Background(), id)
+	if stored.Version != 2 || stored.Battery != 50 {
+		t.Errorf("expected battery 50 at version 2, got %+v", stored)
+	}
+
+	read.Battery = 20
+	err = repo.UpdateScooter(context.Background(), read)
+	if !errors.Is(err, telemetry.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict on stale update, got %v", err)
+	}
+}
+
 func TestFindScootersInArea(t *testing.T) {
 	id1 := uuid.New()
 	id2 := uuid.New()
@@ -117,11 +151,12 @@ func TestFindScootersInArea(t *testing.T) {
 	id4 := uuid.New()
 
 	tests := []struct {
-		name    string
-		initial map[uuid.UUID]telemetry.Scooter
-		area    telemetry.Area
-		status  telemetry.Status
-		wantIDs []uuid.UUID
+		name       string
+		initial    map[uuid.UUID]telemetry.Scooter
+		area       telemetry.Area
+		status     telemetry.Status
+		minBattery int
+		wantIDs    []uuid.UUID
 	}{
 		{
 			name: "one inside area and status",
@@ -165,12 +200,27 @@ func TestFindScootersInArea(t *testing.T) {
 			status:  telemetry.StatusFree,
 			wantIDs: []uuid.UUID{id1, id2},
 		},
+		{
+			name: "min battery",
+			initial: map[uuid.UUID]telemetry.Scooter{
+				id1: {ID: id1, Lat: 51.1, Lng: 17.1, Status: telemetry.StatusFree, Battery: 80},
+				id2: {ID: id2, Lat: 51.2, Lng: 17.2, Status: telemetry.StatusFree, Battery: 20},
+			},
+			area: telemetry.Area{
+				MinLat: 51.0, MaxLat: 51.25,
+				MinLng: 17.0, MaxLng: 17.25,
+			},
+			status:     telemetry.StatusFree,
+			minBattery: 50,
+			wantIDs:    []uuid.UUID{id1},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := mem.NewTelemetryRepo(tt.initial)
-			got, err := repo.FindScootersInArea(context.Background(), tt.area, tt.status)
+			qry := telemetry.Query{Area: tt.area, Status: tt.status, MinBattery: tt.minBattery}
+			got, err := repo.FindScootersInArea(context.Background(), qry)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -250,4 +300,304 @@ func TestStoreEvent(t *testing.T) {
 			t.Errorf("event %d does not match: got %+v, want %+v", i, storedEvent, event)
 		}
 	}
+
+	err := repo.StoreEvent(context.Background(), events[0])
+	if !errors.Is(err, telemetry.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a duplicated event, got %v", err)
+	}
+}
+
+func TestProcessedEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewTelemetryRepo()
+	now := time.Now()
+
+	p := telemetry.ProcessedEvent{
+		ClientID:  "rider-1",
+		Key:       "evt-1",
+		ScooterID: uuid.New(),
+		Type:      telemetry.EventTripEnd,
+		Result:    telemetry.EventResult{EventID: uuid.New(), Status: telemetry.StatusFree, Trip: &telemetry.Trip{Distance: 10}},
+		CreatedAt: now,
+	}
+
+	err := repo.SaveProcessedEvent(ctx, p)
+	if err != nil {
+		t.Fatalf("SaveProcessedEvent() error = %v", err)
+	}
+
+	got, err := repo.GetProcessedEvent(ctx, "rider-1", "evt-1", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("GetProcessedEvent() error = %v", err)
+	}
+
+	if got.Result.EventID != p.Result.EventID || got.Result.Trip == nil || got.Result.Trip.Distance != 10 {
+		t.Errorf("GetProcessedEvent() = %+v, want %+v", got, p)
+	}
+
+	_, err = repo.GetProcessedEvent(ctx, "rider-2", "evt-1", now.Add(-time.Minute))
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected keys scoped by client, got %v", err)
+	}
+
+	_, err = repo.GetProcessedEvent(ctx, "rider-1", "evt-1", now.Add(time.Second))
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound outside the window, got %v", err)
+	}
+
+	n, err := repo.DeleteProcessedEvents(ctx, now.Add(time.Second))
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteProcessedEvents() = %d, %v, want 1", n, err)
+	}
+
+	_, err = repo.GetProcessedEvent(ctx, "rider-1", "evt-1", time.Time{})
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after purge, got %v", err)
+	}
+}
+
+func TestTrips(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewTelemetryRepo()
+	scooterID := uuid.New()
+
+	trip := telemetry.Trip{ScooterID: scooterID, ClientID: "rider-1"}
+	trip.GenCreateVals(time.Now())
+
+	if err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	active, err := repo.GetActiveTrip(ctx, scooterID)
+	if err != nil || active.ID != trip.ID {
+		t.Fatalf("expected active trip %v, got %v (err: %v)", trip.ID, active.ID, err)
+	}
+
+	active.Close(telemetry.Point{Lat: 1, Lng: 1}, time.Now())
+	if err := repo.UpdateTrip(ctx, active); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = repo.GetActiveTrip(ctx, scooterID)
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for closed trip, got %v", err)
+	}
+
+	stored, err := repo.GetTrip(ctx, trip.ID)
+	if err != nil || stored.Active() {
+		t.Errorf("expected closed trip, got %+v (err: %v)", stored, err)
+	}
+
+	_, err = repo.GetTrip(ctx, uuid.New())
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	err = repo.UpdateTrip(ctx, telemetry.Trip{ID: uuid.New()})
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound updating unknown trip, got %v", err)
+	}
+
+	found, err := repo.FindTrips(ctx, telemetry.TripQuery{ClientID: "rider-1"})
+	if err != nil || len(found) != 1 {
+		t.Errorf("expected 1 trip for rider-1, got %d (err: %v)", len(found), err)
+	}
+}
+
+func TestReservations(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewTelemetryRepo()
+
+	active := telemetry.Reservation{ScooterID: uuid.New(), ClientID: "rider-1"}
+	active.GenCreateVals(time.Hour)
+	expired := telemetry.Reservation{ScooterID: uuid.New(), ClientID: "rider-2"}
+	expired.GenCreateVals(-time.Minute)
+
+	for _, res := range []telemetry.Reservation{active, expired} {
+		if err := repo.CreateReservation(ctx, res); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	got, err := repo.GetReservation(ctx, active.ScooterID)
+	if err != nil || got.ID != active.ID {
+		t.Errorf("expected reservation %v, got %v (err: %v)", active.ID, got.ID, err)
+	}
+
+	found, err := repo.FindExpiredReservations(ctx, time.Now())
+	if err != nil || len(found) != 1 || found[0].ID != expired.ID {
+		t.Errorf("expected only %v to be expired, got %+v (err: %v)", expired.ID, found, err)
+	}
+
+	if err := repo.DeleteReservation(ctx, active.ScooterID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = repo.GetReservation(ctx, active.ScooterID)
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestZones(t *testing.T) {
+	ctx := context.Background()
+	repo := mem.NewTelemetryRepo()
+
+	square := []telemetry.Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 1}, {Lat: 1, Lng: 1}, {Lat: 1, Lng: 0}}
+	active := telemetry.Zone{Name: "active", Rule: telemetry.RuleNoParking, Polygon: square, Active: true}
+	active.GenCreateVals()
+	inactive := telemetry.Zone{Name: "inactive", Rule: telemetry.RuleNoParking, Polygon: square}
+	inactive.GenCreateVals()
+
+	for _, z := range []telemetry.Zone{active, inactive} {
+		if err := repo.CreateZone(ctx, z); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	found, err := repo.FindZonesContaining(ctx, telemetry.Point{Lat: 0.5, Lng: 0.5})
+	if err != nil || len(found) != 1 || found[0].ID != active.ID {
+		t.Errorf("expected only the active zone, got %+v (err: %v)", found, err)
+	}
+
+	found, err = repo.FindZonesContaining(ctx, telemetry.Point{Lat: 2, Lng: 2})
+	if err != nil || len(found) != 0 {
+		t.Errorf("expected no zones outside the polygon, got %+v (err: %v)", found, err)
+	}
+
+	zones, err := repo.ListZones(ctx)
+	if err != nil || len(zones) != 2 || zones[0].Name != "active" {
+		t.Errorf("expected 2 zones ordered by name, got %+v (err: %v)", zones, err)
+	}
+
+	if err := repo.DeleteZone(ctx, active.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := repo.GetZone(ctx, active.ID); !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	if err := repo.DeleteZone(ctx, active.ID); !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestFindNearbyScooters(t *testing.T) {
+	center := telemetry.Point{Lat: 45.0, Lng: -75.0}
+	// Roughly 111m per 0.001 degree of latitude.
+	far := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.003, Lng: -75.0, Battery: 80}
+	near := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.001, Lng: -75.0, Battery: 80}
+	mid := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusOccupied, Lat: 45.0, Lng: -75.0025, Battery: 80}
+	retired := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusDecommissioned, Lat: 45.0, Lng: -75.0, Battery: 80}
+	outside := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.01, Lng: -75.0, Battery: 80}
+
+	repo := mem.NewTelemetryRepo(map[uuid.UUID]telemetry.Scooter{
+		far.ID: far, near.ID: near, mid.ID: mid, retired.ID: retired, outside.ID: outside,
+	})
+
+	tests := []struct {
+		name string
+		qry  telemetry.NearbyQuery
+		want []uuid.UUID
+	}{
+		{
+			name: "sorted by distance within radius",
+			qry:  telemetry.NearbyQuery{Center: center, Radius: 500, Limit: 10},
+			want: []uuid.UUID{near.ID, mid.ID, far.ID},
+		},
+		{
+			name: "limit",
+			qry:  telemetry.NearbyQuery{Center: center, Radius: 500, Limit: 2},
+			want: []uuid.UUID{near.ID, mid.ID},
+		},
+		{
+			name: "status filter",
+			qry:  telemetry.NearbyQuery{Center: center, Radius: 500, Limit: 10, Status: telemetry.StatusFree},
+			want: []uuid.UUID{near.ID, far.ID},
+		},
+		{
+			name: "decommissioned on request",
+			qry:  telemetry.NearbyQuery{Center: center, Radius: 500, Limit: 10, Status: telemetry.StatusDecommissioned},
+			want: []uuid.UUID{retired.ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindNearbyScooters(context.Background(), tt.qry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d scooters, got %d", len(tt.want), len(got))
+			}
+
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("result %d: expected %s, got %s", i, id, got[i].ID)
+				}
+
+				wantDistance := telemetry.Distance(center, got[i].Location())
+				if got[i].Distance != wantDistance {
+					t.Errorf("result %d: distance = %v, want %v", i, got[i].Distance, wantDistance)
+				}
+			}
+		})
+	}
+}
+
+func TestCountScootersByCell(t *testing.T) {
+	area := telemetry.Area{MinLat: 45.0, MinLng: -75.0, MaxLat: 45.2, MaxLng: -74.8}
+	scooters := []telemetry.Scooter{
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.01, Lng: -74.99},           // cell 0,0
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.05, Lng: -74.95},           // cell 0,0
+		{ID: uuid.New(), Status: telemetry.StatusOccupied, Lat: 45.09, Lng: -74.91},       // cell 0,0
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.15, Lng: -74.95},           // cell 1,0
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.2, Lng: -74.8},             // north-east corner, cell 1,1
+		{ID: uuid.New(), Status: telemetry.StatusDecommissioned, Lat: 45.01, Lng: -74.99}, // left out by default
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.3, Lng: -74.95},            // outside
+	}
+
+	initial := make(map[uuid.UUID]telemetry.Scooter)
+	for _, s := range scooters {
+		initial[s.ID] = s
+	}
+	repo := mem.NewTelemetryRepo(initial)
+
+	tests := []struct {
+		name   string
+		status telemetry.Status
+		want   []telemetry.CellCount
+	}{
+		{
+			name: "all statuses",
+			want: []telemetry.CellCount{
+				{Row: 0, Col: 0, Status: telemetry.StatusFree, Count: 2},
+				{Row: 0, Col: 0, Status: telemetry.StatusOccupied, Count: 1},
+				{Row: 1, Col: 0, Status: telemetry.StatusFree, Count: 1},
+				{Row: 1, Col: 1, Status: telemetry.StatusFree, Count: 1},
+			},
+		},
+		{
+			name:   "status filter",
+			status: telemetry.StatusDecommissioned,
+			want:   []telemetry.CellCount{{Row: 0, Col: 0, Status: telemetry.StatusDecommissioned, Count: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qry := telemetry.GridQuery{Area: area, CellSize: 0.1, Status: tt.status}
+			got, err := repo.CountScootersByCell(context.Background(), qry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CountScootersByCell() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
 }
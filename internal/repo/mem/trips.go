@@ -0,0 +1,83 @@
+package mem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func (r *TelemetryRepo) CreateTrip(ctx context.Context, t telemetry.Trip) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trips[t.ID] = copyTrip(t)
+	return nil
+}
+
+func (r *TelemetryRepo) UpdateTrip(ctx context.Context, t telemetry.Trip) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trips[t.ID]; !ok {
+		return telemetry.ErrNotFound
+	}
+
+	r.trips[t.ID] = copyTrip(t)
+	return nil
+}
+
+func (r *TelemetryRepo) GetTrip(ctx context.Context, id uuid.UUID) (telemetry.Trip, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.trips[id]
+	if !ok {
+		return telemetry.Trip{}, telemetry.ErrNotFound
+	}
+
+	return copyTrip(t), nil
+}
+
+func (r *TelemetryRepo) GetActiveTrip(ctx context.Context, scooterID uuid.UUID) (telemetry.Trip, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.trips {
+		if t.ScooterID == scooterID && t.Active() {
+			return copyTrip(t), nil
+		}
+	}
+
+	return telemetry.Trip{}, telemetry.ErrNotFound
+}
+
+// FindTrips returns the trips matching the query ordered by start time.
+func (r *TelemetryRepo) FindTrips(ctx context.Context, qry telemetry.TripQuery) ([]telemetry.Trip, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Trip
+	for _, t := range r.trips {
+		if qry.Match(t) {
+			result = append(result, copyTrip(t))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.Before(result[j].StartedAt)
+	})
+
+	return result, nil
+}
+
+// copyTrip detaches the trip path and fare from the caller so stored trips cannot be
+// mutated from outside the repo.
+func copyTrip(t telemetry.Trip) telemetry.Trip {
+	t.Path = append([]telemetry.Point(nil), t.Path...)
+	if t.Fare != nil {
+		fare := *t.Fare
+		t.Fare = &fare
+	}
+	return t
+}
@@ -0,0 +1,111 @@
+package mem
+
+import (
+	"context"
+	"sort"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func (r *TelemetryRepo) CreateZone(ctx context.Context, z telemetry.Zone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.zones[z.ID] = copyZone(z)
+	return nil
+}
+
+func (r *TelemetryRepo) UpdateZone(ctx context.Context, z telemetry.Zone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zones[z.ID]; !ok {
+		return telemetry.ErrNotFound
+	}
+
+	r.zones[z.ID] = copyZone(z)
+	return nil
+}
+
+func (r *TelemetryRepo) GetZone(ctx context.Context, id uuid.UUID) (telemetry.Zone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	z, ok := r.zones[id]
+	if !ok {
+		return telemetry.Zone{}, telemetry.ErrNotFound
+	}
+
+	return copyZone(z), nil
+}
+
+func (r *TelemetryRepo) DeleteZone(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.zones[id]; !ok {
+		return telemetry.ErrNotFound
+	}
+
+	delete(r.zones, id)
+	return nil
+}
+
+// ListZones returns all zones ordered by name.
+func (r *TelemetryRepo) ListZones(ctx context.Context) ([]telemetry.Zone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Zone
+	for _, z := range r.zones {
+		result = append(result, copyZone(z))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func (r *TelemetryRepo) FindZonesContaining(ctx context.Context, p telemetry.Point) ([]telemetry.Zone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Zone
+	for _, z := range r.zones {
+		if z.Active && z.Contains(p) {
+			result = append(result, copyZone(z))
+		}
+	}
+
+	return result, nil
+}
+
+func (r *TelemetryRepo) CreateViolation(ctx context.Context, v telemetry.Violation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations = append(r.violations, v)
+	return nil
+}
+
+// FindViolations returns the violations matching the query in the order they
+// were recorded.
+func (r *TelemetryRepo) FindViolations(ctx context.Context, qry telemetry.ViolationQuery) ([]telemetry.Violation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []telemetry.Violation
+	for _, v := range r.violations {
+		if qry.Match(v) {
+			result = append(result, v)
+		}
+	}
+
+	return result, nil
+}
+
+func copyZone(z telemetry.Zone) telemetry.Zone {
+	z.Polygon = append([]telemetry.Point(nil), z.Polygon...)
+	return z
+}
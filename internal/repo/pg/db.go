@@ -3,7 +3,9 @@ package pg
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/adrianpk/rida/internal/cfg"
 	"github.com/jmoiron/sqlx"
@@ -29,6 +31,7 @@ func (db *DB) Setup(ctx context.Context) error {
 	for i := 0; i < 10; i++ {
 		pgdb, err = sqlx.ConnectContext(ctx, "postgres", db.cfg.Pg.DSN())
 		if err == nil {
+			pgdb.MapperFunc(snakeCase)
 			db.DB = pgdb
 			return nil
 		}
@@ -44,3 +47,24 @@ func (db *DB) Setup(ctx context.Context) error {
 
 	return fmt.Errorf("postgres connection failed after 10 attempts: %w", err)
 }
+
+// snakeCase maps Go field names to column names (e.g. ScooterID -> scooter_id,
+// UpdatedAt -> updated_at) so domain structs can be scanned and bound without
+// db tags.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
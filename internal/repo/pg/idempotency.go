@@ -0,0 +1,83 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+// processedEventRow is the processed_events table representation of a
+// telemetry.ProcessedEvent. The result is stored as a JSONB object.
+type processedEventRow struct {
+	ClientID  string    `db:"client_id"`
+	Key       string    `db:"key"`
+	ScooterID uuid.UUID `db:"scooter_id"`
+	Type      string    `db:"type"`
+	Result    []byte    `db:"result"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (r *TelemetryRepo) GetProcessedEvent(ctx context.Context, clientID, key string, since time.Time) (telemetry.ProcessedEvent, error) {
+	var row processedEventRow
+	q := query[getProcessedEventQueryKey]
+	err := r.conn(ctx).GetContext(ctx, &row, q, clientID, key, since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return telemetry.ProcessedEvent{}, telemetry.ErrNotFound
+	}
+
+	if err != nil {
+		return telemetry.ProcessedEvent{}, err
+	}
+
+	p := telemetry.ProcessedEvent{
+		ClientID:  row.ClientID,
+		Key:       row.Key,
+		ScooterID: row.ScooterID,
+		Type:      telemetry.EventType(row.Type),
+		CreatedAt: row.CreatedAt,
+	}
+
+	err = json.Unmarshal(row.Result, &p.Result)
+	if err != nil {
+		return telemetry.ProcessedEvent{}, err
+	}
+
+	return p, nil
+}
+
+func (r *TelemetryRepo) SaveProcessedEvent(ctx context.Context, p telemetry.ProcessedEvent) error {
+	result, err := json.Marshal(p.Result)
+	if err != nil {
+		return err
+	}
+
+	row := processedEventRow{
+		ClientID:  p.ClientID,
+		Key:       p.Key,
+		ScooterID: p.ScooterID,
+		Type:      string(p.Type),
+		Result:    result,
+		CreatedAt: p.CreatedAt,
+	}
+
+	q := query[saveProcessedEventQueryKey]
+	_, err = r.conn(ctx).NamedExecContext(ctx, q, row)
+
+	return err
+}
+
+func (r *TelemetryRepo) DeleteProcessedEvents(ctx context.Context, before time.Time) (int, error) {
+	q := query[deleteProcessedEventsQueryKey]
+	res, err := r.conn(ctx).ExecContext(ctx, q, before)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -5,7 +5,8 @@ import (
 	"fmt"
 )
 
-// Migrate creates the tables needed for Scooter and Event in a simple way.
+// Migrate creates the tables needed for Scooter, Event, Trip, Reservation, Zone,
+// Violation and ProcessedEvent in a simple way.
 // This is a basic implementation just to satisfy the use case for this project.
 func (r *TelemetryRepo) Migrate(ctx context.Context) error {
 	queries := []string{
@@ -25,6 +26,122 @@ func (r *TelemetryRepo) Migrate(ctx context.Context) error {
 			lat DOUBLE PRECISION NOT NULL,
 			lng DOUBLE PRECISION NOT NULL
 		);`,
+		`ALTER TABLE scooters ADD COLUMN IF NOT EXISTS battery SMALLINT NOT NULL DEFAULT 100;`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS battery SMALLINT;`,
+		// Rows with statuses unknown to the current model are taken out of
+		// circulation before the status set is enforced.
+		`UPDATE scooters SET status = 'offline'
+			WHERE status NOT IN ('free', 'occupied', 'reserved', 'maintenance', 'offline', 'low_battery', 'decommissioned');`,
+		`ALTER TABLE scooters DROP CONSTRAINT IF EXISTS scooters_status_check;`,
+		`ALTER TABLE scooters ADD CONSTRAINT scooters_status_check
+			CHECK (status IN ('free', 'occupied', 'reserved', 'maintenance', 'offline', 'low_battery', 'decommissioned'));`,
+		`ALTER TABLE scooters ADD COLUMN IF NOT EXISTS location geography(Point, 4326)
+			GENERATED ALWAYS AS (CAST(ST_SetSRID(ST_MakePoint(lng, lat), 4326) AS geography)) STORED;`,
+		`CREATE INDEX IF NOT EXISTS scooters_location_idx ON scooters USING GIST (location);`,
+		`ALTER TABLE scooters ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;`,
+		`CREATE TABLE IF NOT EXISTS trips (
+			id UUID PRIMARY KEY,
+			scooter_id UUID NOT NULL,
+			client_id TEXT NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ,
+			start_lat DOUBLE PRECISION NOT NULL,
+			start_lng DOUBLE PRECISION NOT NULL,
+			end_lat DOUBLE PRECISION,
+			end_lng DOUBLE PRECISION,
+			distance DOUBLE PRECISION NOT NULL DEFAULT 0,
+			path JSONB NOT NULL DEFAULT '[]'
+		);`,
+		`CREATE INDEX IF NOT EXISTS trips_scooter_started_idx ON trips (scooter_id, started_at);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS trips_active_scooter_idx ON trips (scooter_id) WHERE ended_at IS NULL;`,
+		`ALTER TABLE trips ADD COLUMN IF NOT EXISTS fare JSONB;`,
+		`CREATE TABLE IF NOT EXISTS reservations (
+			id UUID PRIMARY KEY,
+			scooter_id UUID NOT NULL UNIQUE,
+			client_id TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS reservations_expires_at_idx ON reservations (expires_at);`,
+		`CREATE TABLE IF NOT EXISTS zones (
+			id UUID PRIMARY KEY,
+			name TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			max_speed DOUBLE PRECISION NOT NULL DEFAULT 0,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			area geometry(Polygon, 4326) NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS zones_area_idx ON zones USING GIST (area);`,
+		// Violations keep the zone name and rule so they remain provable
+		// after the zone is changed or deleted.
+		`CREATE TABLE IF NOT EXISTS violations (
+			id UUID PRIMARY KEY,
+			zone_id UUID NOT NULL,
+			zone_name TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			scooter_id UUID NOT NULL,
+			trip_id UUID,
+			event_id UUID NOT NULL,
+			lat DOUBLE PRECISION NOT NULL,
+			lng DOUBLE PRECISION NOT NULL,
+			speed DOUBLE PRECISION NOT NULL DEFAULT 0,
+			occurred_at TIMESTAMPTZ NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS violations_scooter_idx ON violations (scooter_id, occurred_at);`,
+		`CREATE INDEX IF NOT EXISTS violations_trip_idx ON violations (trip_id);`,
+		// Events keep the device time and the server reception time apart.
+		// The former timestamp column held the reception time, written in UTC.
+		`DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'events' AND column_name = 'timestamp') THEN
+				ALTER TABLE events RENAME COLUMN timestamp TO occurred_at;
+			END IF;
+			IF EXISTS (SELECT 1 FROM information_schema.columns
+				WHERE table_name = 'events' AND column_name = 'occurred_at'
+				AND data_type = 'timestamp without time zone') THEN
+				ALTER TABLE events ALTER COLUMN occurred_at TYPE TIMESTAMPTZ USING occurred_at AT TIME ZONE 'UTC';
+			END IF;
+		END $$;`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS received_at TIMESTAMPTZ;`,
+		`UPDATE events SET received_at = occurred_at WHERE received_at IS NULL;`,
+		`ALTER TABLE events ALTER COLUMN received_at SET NOT NULL;`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS stale BOOLEAN NOT NULL DEFAULT FALSE;`,
+		`ALTER TABLE scooters ADD COLUMN IF NOT EXISTS last_event_at TIMESTAMPTZ;`,
+		`CREATE TABLE IF NOT EXISTS processed_events (
+			client_id TEXT NOT NULL,
+			key TEXT NOT NULL,
+			scooter_id UUID NOT NULL,
+			type TEXT NOT NULL,
+			result JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (client_id, key)
+		);`,
+		`CREATE INDEX IF NOT EXISTS processed_events_created_at_idx ON processed_events (created_at);`,
+		`CREATE INDEX IF NOT EXISTS events_scooter_id_occurred_at_idx ON events (scooter_id, occurred_at);`,
+		`ALTER TABLE scooters ADD COLUMN IF NOT EXISTS located_at TIMESTAMPTZ;`,
+		// Trip, reservation, zone, violation and idempotency times are
+		// compared with times carrying a zone, so they carry one too. Like
+		// the event times, the stored values were written in UTC.
+		`DO $$
+		DECLARE
+			col RECORD;
+		BEGIN
+			FOR col IN SELECT table_name, column_name FROM information_schema.columns
+				WHERE data_type = 'timestamp without time zone'
+				AND (table_name, column_name) IN (
+					('trips', 'started_at'), ('trips', 'ended_at'),
+					('reservations', 'created_at'), ('reservations', 'expires_at'),
+					('zones', 'created_at'), ('zones', 'updated_at'),
+					('violations', 'occurred_at'),
+					('processed_events', 'created_at'))
+			LOOP
+				EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE ''UTC''',
+					col.table_name, col.column_name, col.column_name);
+			END LOOP;
+		END $$;`,
 	}
 
 	for _, q := range queries {
@@ -1,23 +1,192 @@
 package pg
 
 const (
-	getScooterQueryKey         = "GetScooter"
-	updateScooterQueryKey      = "UpdateScooter"
-	findScootersInAreaQueryKey = "FindScootersInArea"
-	storeEventQueryKey         = "StoreEvent"
+	getScooterQueryKey          = "GetScooter"
+	lockScooterQueryKey         = "LockScooter"
+	createScooterQueryKey       = "CreateScooter"
+	updateScooterQueryKey       = "UpdateScooter"
+	deleteScooterQueryKey       = "DeleteScooter"
+	findScootersInAreaQueryKey  = "FindScootersInArea"
+	findNearbyScootersQueryKey  = "FindNearbyScooters"
+	countScootersByCellQueryKey = "CountScootersByCell"
+	listScootersQueryKey        = "ListScooters"
+	storeEventQueryKey          = "StoreEvent"
+	findEventsQueryKey          = "FindEvents"
+	createTripQueryKey          = "CreateTrip"
+	updateTripQueryKey          = "UpdateTrip"
+	getTripQueryKey             = "GetTrip"
+	getActiveTripQueryKey       = "GetActiveTrip"
+	findTripsQueryKey           = "FindTrips"
+
+	createReservationQueryKey       = "CreateReservation"
+	getReservationQueryKey          = "GetReservation"
+	deleteReservationQueryKey       = "DeleteReservation"
+	findExpiredReservationsQueryKey = "FindExpiredReservations"
+
+	getProcessedEventQueryKey     = "GetProcessedEvent"
+	saveProcessedEventQueryKey    = "SaveProcessedEvent"
+	deleteProcessedEventsQueryKey = "DeleteProcessedEvents"
+
+	createZoneQueryKey          = "CreateZone"
+	updateZoneQueryKey          = "UpdateZone"
+	getZoneQueryKey             = "GetZone"
+	deleteZoneQueryKey          = "DeleteZone"
+	listZonesQueryKey           = "ListZones"
+	findZonesContainingQueryKey = "FindZonesContaining"
+	createViolationQueryKey     = "CreateViolation"
+	findViolationsQueryKey      = "FindViolations"
 )
 
+// scooterColumns selects a scooter leaving out derived columns such as the
+// indexed location.
+const scooterColumns = `id, status, lat, lng, battery, updated_at, version, last_event_at, located_at`
+
+// eventColumns selects an event.
+const eventColumns = `id, scooter_id, type, occurred_at, received_at, lat, lng, battery, stale`
+
+// centerPoint is the :lat/:lng query center as a geography point.
+const centerPoint = `CAST(ST_SetSRID(ST_MakePoint(:lng, :lat), 4326) AS geography)`
+
+// zoneColumns selects a zone with its polygon rendered as GeoJSON.
+const zoneColumns = `id, name, rule, max_speed, active, ST_AsGeoJSON(area) AS area, created_at, updated_at`
+
 var query = map[string]string{
-	getScooterQueryKey:    `SELECT * FROM scooters WHERE id = $1`,
-	updateScooterQueryKey: `UPDATE scooters SET status = :status, lat = :lat, lng = :lng, updated_at = :updated_at WHERE id = :id`,
+	getScooterQueryKey:    `SELECT ` + scooterColumns + ` FROM scooters WHERE id = $1`,
+	lockScooterQueryKey:   `SELECT ` + scooterColumns + ` FROM scooters WHERE id = $1 FOR UPDATE`,
+	createScooterQueryKey: `INSERT INTO scooters (id, status, lat, lng, battery, updated_at, version, last_event_at, located_at) VALUES (:id, :status, :lat, :lng, :battery, :updated_at, :version, :last_event_at, :located_at)`,
+	deleteScooterQueryKey: `UPDATE scooters SET status = 'decommissioned', updated_at = $2, version = version + 1 WHERE id = $1`,
+	updateScooterQueryKey: `
+UPDATE scooters
+SET status = :status, lat = :lat, lng = :lng, battery = :battery, updated_at = :updated_at, last_event_at = :last_event_at, located_at = :located_at, version = version + 1
+WHERE id = :id AND version = :version
+`,
 	findScootersInAreaQueryKey: `
-SELECT id, status, lat, lng, updated_at
+SELECT ` + scooterColumns + `
 FROM scooters
-WHERE status = :status
+WHERE ((:status = '' AND status <> 'decommissioned') OR status = :status)
+  AND battery >= :min_battery
   AND ST_Within(
     ST_SetSRID(ST_MakePoint(lng, lat), 4326),
     ST_MakeEnvelope(:min_lng, :min_lat, :max_lng, :max_lat, 4326)
   )
+  AND (CAST(:after AS UUID) IS NULL OR id > :after)
+ORDER BY id
+LIMIT NULLIF(:limit, 0)
+`,
+	// The center is repeated inline so the KNN ordering can use the location
+	// index; distances are computed on the sphere to match the mem repo.
+	findNearbyScootersQueryKey: `
+SELECT ` + scooterColumns + `, ST_Distance(location, ` + centerPoint + `, false) AS distance
+FROM scooters
+WHERE ST_DWithin(location, ` + centerPoint + `, :radius, false)
+  AND ((:status = '' AND status <> 'decommissioned') OR status = :status)
+  AND battery >= :min_battery
+ORDER BY location <-> ` + centerPoint + `, id
+LIMIT :limit
+`,
+	// Cells are counted from the south-west corner of the area like in
+	// GridQuery.Cell, points on the north and east borders clamped to the
+	// last row and column.
+	countScootersByCellQueryKey: `
+SELECT
+  CAST(LEAST(FLOOR((lat - :min_lat) / :cell_size), :rows - 1) AS INT) AS "row",
+  CAST(LEAST(FLOOR((lng - :min_lng) / :cell_size), :cols - 1) AS INT) AS col,
+  status,
+  COUNT(*) AS count
+FROM scooters
+WHERE ((:status = '' AND status <> 'decommissioned') OR status = :status)
+  AND ST_Intersects(
+    ST_SetSRID(ST_MakePoint(lng, lat), 4326),
+    ST_MakeEnvelope(:min_lng, :min_lat, :max_lng, :max_lat, 4326)
+  )
+GROUP BY 1, 2, status
+ORDER BY 1, 2, status
+`,
+	listScootersQueryKey: `SELECT ` + scooterColumns + ` FROM scooters ORDER BY id`,
+	storeEventQueryKey: `
+INSERT INTO events (id, scooter_id, type, occurred_at, received_at, lat, lng, battery, stale)
+VALUES (:id, :scooter_id, :type, :occurred_at, :received_at, :lat, :lng, :battery, :stale)
+`,
+	createTripQueryKey: `
+INSERT INTO trips (id, scooter_id, client_id, started_at, ended_at, start_lat, start_lng, end_lat, end_lng, distance, path, fare)
+VALUES (:id, :scooter_id, :client_id, :started_at, :ended_at, :start_lat, :start_lng, :end_lat, :end_lng, :distance, :path, :fare)
+`,
+	updateTripQueryKey: `
+UPDATE trips
+SET ended_at = :ended_at, end_lat = :end_lat, end_lng = :end_lng, distance = :distance, path = :path, fare = :fare
+WHERE id = :id
+`,
+	getTripQueryKey:       `SELECT * FROM trips WHERE id = $1`,
+	getActiveTripQueryKey: `SELECT * FROM trips WHERE scooter_id = $1 AND ended_at IS NULL`,
+	findEventsQueryKey: `
+SELECT ` + eventColumns + `
+FROM (
+  SELECT ` + eventColumns + `
+  FROM events
+  WHERE scooter_id = :scooter_id
+    AND (:type = '' OR type = :type)
+    AND (CAST(:from AS TIMESTAMPTZ) IS NULL OR occurred_at >= :from)
+    AND (CAST(:to AS TIMESTAMPTZ) IS NULL OR occurred_at < :to)
+  ORDER BY
+    CASE WHEN CAST(:latest AS BOOLEAN) THEN occurred_at END DESC,
+    CASE WHEN CAST(:latest AS BOOLEAN) THEN received_at END DESC,
+    occurred_at, received_at
+  LIMIT :limit
+) e
+ORDER BY occurred_at, received_at
+`,
+	findTripsQueryKey: `
+SELECT *
+FROM trips
+WHERE (CAST(:scooter_id AS UUID) IS NULL OR scooter_id = :scooter_id)
+  AND (:client_id = '' OR client_id = :client_id)
+  AND (CAST(:from AS TIMESTAMPTZ) IS NULL OR started_at >= :from)
+  AND (CAST(:to AS TIMESTAMPTZ) IS NULL OR started_at < :to)
+ORDER BY started_at
+`,
+	createReservationQueryKey: `
+INSERT INTO reservations (id, scooter_id, client_id, created_at, expires_at)
+VALUES (:id, :scooter_id, :client_id, :created_at, :expires_at)
+`,
+	getReservationQueryKey:          `SELECT * FROM reservations WHERE scooter_id = $1`,
+	deleteReservationQueryKey:       `DELETE FROM reservations WHERE scooter_id = $1`,
+	findExpiredReservationsQueryKey: `SELECT * FROM reservations WHERE expires_at <= $1`,
+	getProcessedEventQueryKey:       `SELECT * FROM processed_events WHERE client_id = $1 AND key = $2 AND created_at >= $3`,
+	saveProcessedEventQueryKey: `
+INSERT INTO processed_events (client_id, key, scooter_id, type, result, created_at)
+VALUES (:client_id, :key, :scooter_id, :type, :result, :created_at)
+ON CONFLICT (client_id, key) DO UPDATE
+SET scooter_id = EXCLUDED.scooter_id, type = EXCLUDED.type, result = EXCLUDED.result, created_at = EXCLUDED.created_at
+`,
+	deleteProcessedEventsQueryKey: `DELETE FROM processed_events WHERE created_at < $1`,
+	createZoneQueryKey: `
+INSERT INTO zones (id, name, rule, max_speed, active, area, created_at, updated_at)
+VALUES (:id, :name, :rule, :max_speed, :active, ST_SetSRID(ST_GeomFromGeoJSON(:area), 4326), :created_at, :updated_at)
+`,
+	updateZoneQueryKey: `
+UPDATE zones
+SET name = :name, rule = :rule, max_speed = :max_speed, active = :active,
+    area = ST_SetSRID(ST_GeomFromGeoJSON(:area), 4326), updated_at = :updated_at
+WHERE id = :id
+`,
+	getZoneQueryKey:    `SELECT ` + zoneColumns + ` FROM zones WHERE id = $1`,
+	deleteZoneQueryKey: `DELETE FROM zones WHERE id = $1`,
+	listZonesQueryKey:  `SELECT ` + zoneColumns + ` FROM zones ORDER BY name`,
+	findZonesContainingQueryKey: `
+SELECT ` + zoneColumns + `
+FROM zones
+WHERE active
+  AND ST_Contains(area, ST_SetSRID(ST_MakePoint($1, $2), 4326))
+`,
+	createViolationQueryKey: `
+INSERT INTO violations (id, zone_id, zone_name, rule, scooter_id, trip_id, event_id, lat, lng, speed, occurred_at)
+VALUES (:id, :zone_id, :zone_name, :rule, :scooter_id, :trip_id, :event_id, :lat, :lng, :speed, :occurred_at)
+`,
+	findViolationsQueryKey: `
+SELECT *
+FROM violations
+WHERE (CAST(:scooter_id AS UUID) IS NULL OR scooter_id = :scooter_id)
+  AND (CAST(:trip_id AS UUID) IS NULL OR trip_id = :trip_id)
+ORDER BY occurred_at
 `,
-	storeEventQueryKey: `INSERT INTO events (id, scooter_id, type, timestamp, lat, lng) VALUES (:id, :scooter_id, :type, :timestamp, :lat, :lng)`,
 }
@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func (r *TelemetryRepo) CreateReservation(ctx context.Context, res telemetry.Reservation) error {
+	q := query[createReservationQueryKey]
+	_, err := r.conn(ctx).NamedExecContext(ctx, q, res)
+
+	return err
+}
+
+func (r *TelemetryRepo) GetReservation(ctx context.Context, scooterID uuid.UUID) (telemetry.Reservation, error) {
+	var res telemetry.Reservation
+	q := query[getReservationQueryKey]
+	err := r.conn(ctx).GetContext(ctx, &res, q, scooterID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return telemetry.Reservation{}, telemetry.ErrNotFound
+	}
+
+	return res, err
+}
+
+func (r *TelemetryRepo) DeleteReservation(ctx context.Context, scooterID uuid.UUID) error {
+	q := query[deleteReservationQueryKey]
+	_, err := r.conn(ctx).ExecContext(ctx, q, scooterID)
+
+	return err
+}
+
+func (r *TelemetryRepo) FindExpiredReservations(ctx context.Context, at time.Time) ([]telemetry.Reservation, error) {
+	var reservations []telemetry.Reservation
+	q := query[findExpiredReservationsQueryKey]
+	err := r.conn(ctx).SelectContext(ctx, &reservations, q, at)
+
+	return reservations, err
+}
@@ -54,15 +54,16 @@ func (r *TelemetryRepo) SeedCity(ctx context.Context, count int, area telemetry.
 			status = telemetry.StatusOccupied
 		}
 		s := telemetry.Scooter{
-			Status: status,
-			Lat:    area.MinLat + rand.Float64()*(area.MaxLat-area.MinLat),
-			Lng:    area.MinLng + rand.Float64()*(area.MaxLng-area.MinLng),
+			Status:  status,
+			Lat:     area.MinLat + rand.Float64()*(area.MaxLat-area.MinLat),
+			Lng:     area.MinLng + rand.Float64()*(area.MaxLng-area.MinLng),
+			Battery: 30 + rand.Intn(71), // above the low battery threshold
 		}
 		s.GenID()
 		// Insert scooter into DB
 		_, err := r.db.ExecContext(ctx,
-			`INSERT INTO scooters (id, status, lat, lng, updated_at) VALUES ($1, $2, $3, $4, $5)`,
-			s.ID, s.Status, s.Lat, s.Lng, s.UpdatedAt,
+			`INSERT INTO scooters (id, status, lat, lng, battery, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			s.ID, s.Status, s.Lat, s.Lng, s.Battery, s.UpdatedAt,
 		)
 		if err != nil {
 			return err
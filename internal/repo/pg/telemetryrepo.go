@@ -2,11 +2,19 @@ package pg
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/adrianpk/rida/internal/telemetry"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the PostgreSQL error code for duplicate keys.
+const uniqueViolation = "23505"
+
 // TelemetryRepo is a PostgreSQL implementation of the telemetry.Repo interface.
 type TelemetryRepo struct {
 	db *DB
@@ -17,30 +25,120 @@ func NewTelemetryRepo(db *DB) *TelemetryRepo {
 	return &TelemetryRepo{db: db}
 }
 
+// querier runs the repo queries, on the database or on a transaction.
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+type txKey struct{}
+
+// conn returns the transaction of ctx, if any, or the database.
+func (r *TelemetryRepo) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return r.db.DB
+}
+
+// InTx runs fn in a transaction. Calls nested in one join it.
+func (r *TelemetryRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *TelemetryRepo) GetScooter(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error) {
+	return r.getScooter(ctx, getScooterQueryKey, id)
+}
+
+// LockScooter reads the scooter with a row lock, held until the transaction
+// of ctx ends.
+func (r *TelemetryRepo) LockScooter(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error) {
+	return r.getScooter(ctx, lockScooterQueryKey, id)
+}
+
+func (r *TelemetryRepo) getScooter(ctx context.Context, key string, id uuid.UUID) (telemetry.Scooter, error) {
 	var scooter telemetry.Scooter
-	q := query[getScooterQueryKey]
-	err := r.db.GetContext(ctx, &scooter, q, id)
+	err := r.conn(ctx).GetContext(ctx, &scooter, query[key], id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return telemetry.Scooter{}, telemetry.ErrNotFound
+	}
 
 	return scooter, err
 }
 
+func (r *TelemetryRepo) CreateScooter(ctx context.Context, s telemetry.Scooter) error {
+	q := query[createScooterQueryKey]
+	_, err := r.conn(ctx).NamedExecContext(ctx, q, s)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return telemetry.ErrAlreadyExists
+	}
+
+	return err
+}
+
+func (r *TelemetryRepo) DeleteScooter(ctx context.Context, id uuid.UUID) error {
+	q := query[deleteScooterQueryKey]
+	res, err := r.conn(ctx).ExecContext(ctx, q, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
 func (r *TelemetryRepo) UpdateScooter(ctx context.Context, s telemetry.Scooter) error {
 	q := query[updateScooterQueryKey]
-	_, err := r.db.NamedExecContext(ctx, q, s)
+	res, err := r.conn(ctx).NamedExecContext(ctx, q, s)
+	if err != nil {
+		return err
+	}
 
-	return err
+	err = checkAffected(res)
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		return err
+	}
+
+	// Nothing matched: tell a missing scooter apart from a stale version.
+	_, err = r.GetScooter(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	return telemetry.ErrVersionConflict
 }
 
-func (r *TelemetryRepo) FindScootersInArea(ctx context.Context, area telemetry.Area, status telemetry.Status) ([]telemetry.Scooter, error) {
+func (r *TelemetryRepo) FindScootersInArea(ctx context.Context, qry telemetry.Query) ([]telemetry.Scooter, error) {
 	q := query[findScootersInAreaQueryKey]
 	var scooters []telemetry.Scooter
-	rows, err := r.db.NamedQueryContext(ctx, q, map[string]interface{}{
-		"min_lat": area.MinLat,
-		"max_lat": area.MaxLat,
-		"min_lng": area.MinLng,
-		"max_lng": area.MaxLng,
-		"status":  status,
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"min_lat":     qry.Area.MinLat,
+		"max_lat":     qry.Area.MaxLat,
+		"min_lng":     qry.Area.MinLng,
+		"max_lng":     qry.Area.MaxLng,
+		"status":      qry.Status,
+		"min_battery": qry.MinBattery,
+		"after":       nullUUID(qry.After),
+		"limit":       qry.Limit,
 	})
 
 	if err != nil {
@@ -59,13 +157,115 @@ func (r *TelemetryRepo) FindScootersInArea(ctx context.Context, area telemetry.A
 	return scooters, nil
 }
 
+func (r *TelemetryRepo) FindNearbyScooters(ctx context.Context, qry telemetry.NearbyQuery) ([]telemetry.NearbyScooter, error) {
+	q := query[findNearbyScootersQueryKey]
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"lat":         qry.Center.Lat,
+		"lng":         qry.Center.Lng,
+		"radius":      qry.Radius,
+		"limit":       qry.Limit,
+		"status":      qry.Status,
+		"min_battery": qry.MinBattery,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scooters []telemetry.NearbyScooter
+	for rows.Next() {
+		var s telemetry.NearbyScooter
+		if err := rows.StructScan(&s); err != nil {
+			return nil, err
+		}
+		scooters = append(scooters, s)
+	}
+
+	return scooters, rows.Err()
+}
+
+func (r *TelemetryRepo) CountScootersByCell(ctx context.Context, qry telemetry.GridQuery) ([]telemetry.CellCount, error) {
+	rows, cols := qry.Dimensions()
+	q := query[countScootersByCellQueryKey]
+	result, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"min_lat":   qry.Area.MinLat,
+		"min_lng":   qry.Area.MinLng,
+		"max_lat":   qry.Area.MaxLat,
+		"max_lng":   qry.Area.MaxLng,
+		"cell_size": qry.CellSize,
+		"rows":      rows,
+		"cols":      cols,
+		"status":    qry.Status,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var counts []telemetry.CellCount
+	for result.Next() {
+		var c telemetry.CellCount
+		if err := result.StructScan(&c); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, result.Err()
+}
+
+func (r *TelemetryRepo) ListScooters(ctx context.Context) ([]telemetry.Scooter, error) {
+	var scooters []telemetry.Scooter
+	q := query[listScootersQueryKey]
+	err := r.conn(ctx).SelectContext(ctx, &scooters, q)
+
+	return scooters, err
+}
+
 func (r *TelemetryRepo) StoreEvent(ctx context.Context, e telemetry.Event) error {
 	q := query[storeEventQueryKey]
-	_, err := r.db.NamedExecContext(ctx, q, e)
+	_, err := r.conn(ctx).NamedExecContext(ctx, q, e)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return telemetry.ErrAlreadyExists
+	}
 
 	return err
 }
 
+// FindEvents returns the events matching the query in the order they
+// occurred, then in the order they were received.
+func (r *TelemetryRepo) FindEvents(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error) {
+	q := query[findEventsQueryKey]
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"scooter_id": qry.ScooterID,
+		"type":       string(qry.Type),
+		"from":       nullTime(qry.From),
+		"to":         nullTime(qry.To),
+		"limit":      qry.Limit,
+		"latest":     qry.Latest,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []telemetry.Event
+	for rows.Next() {
+		var e telemetry.Event
+		if err := rows.StructScan(&e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
 // Setup runs migration and seeding for the TelemetryRepo.
 func (r *TelemetryRepo) Setup(ctx context.Context) error {
 	err := r.Migrate(ctx)
@@ -0,0 +1,207 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// tripRow is the trips table representation of a telemetry.Trip. The path is
+// stored as a JSONB array of points and the fare, once priced, as a JSONB
+// object.
+type tripRow struct {
+	ID        uuid.UUID       `db:"id"`
+	ScooterID uuid.UUID       `db:"scooter_id"`
+	ClientID  string          `db:"client_id"`
+	StartedAt time.Time       `db:"started_at"`
+	EndedAt   sql.NullTime    `db:"ended_at"`
+	StartLat  float64         `db:"start_lat"`
+	StartLng  float64         `db:"start_lng"`
+	EndLat    sql.NullFloat64 `db:"end_lat"`
+	EndLng    sql.NullFloat64 `db:"end_lng"`
+	Distance  float64         `db:"distance"`
+	Path      []byte          `db:"path"`
+	Fare      []byte          `db:"fare"`
+}
+
+func newTripRow(t telemetry.Trip) (tripRow, error) {
+	path, err := json.Marshal(t.Path)
+	if err != nil {
+		return tripRow{}, err
+	}
+
+	row := tripRow{
+		ID:        t.ID,
+		ScooterID: t.ScooterID,
+		ClientID:  t.ClientID,
+		StartedAt: t.StartedAt,
+		StartLat:  t.Start.Lat,
+		StartLng:  t.Start.Lng,
+		Distance:  t.Distance,
+		Path:      path,
+	}
+
+	if t.EndedAt != nil {
+		row.EndedAt = sql.NullTime{Time: *t.EndedAt, Valid: true}
+	}
+
+	if t.Fare != nil {
+		row.Fare, err = json.Marshal(t.Fare)
+		if err != nil {
+			return tripRow{}, err
+		}
+	}
+
+	if t.End != nil {
+		row.EndLat = sql.NullFloat64{Float64: t.End.Lat, Valid: true}
+		row.EndLng = sql.NullFloat64{Float64: t.End.Lng, Valid: true}
+	}
+
+	return row, nil
+}
+
+func (row tripRow) trip() (telemetry.Trip, error) {
+	t := telemetry.Trip{
+		ID:        row.ID,
+		ScooterID: row.ScooterID,
+		ClientID:  row.ClientID,
+		StartedAt: row.StartedAt,
+		Start:     telemetry.Point{Lat: row.StartLat, Lng: row.StartLng},
+		Distance:  row.Distance,
+	}
+
+	if row.EndedAt.Valid {
+		endedAt := row.EndedAt.Time
+		t.EndedAt = &endedAt
+	}
+
+	if row.EndLat.Valid && row.EndLng.Valid {
+		t.End = &telemetry.Point{Lat: row.EndLat.Float64, Lng: row.EndLng.Float64}
+	}
+
+	if len(row.Fare) > 0 {
+		t.Fare = &telemetry.Fare{}
+		if err := json.Unmarshal(row.Fare, t.Fare); err != nil {
+			return telemetry.Trip{}, err
+		}
+	}
+
+	err := json.Unmarshal(row.Path, &t.Path)
+	return t, err
+}
+
+func (r *TelemetryRepo) CreateTrip(ctx context.Context, t telemetry.Trip) error {
+	row, err := newTripRow(t)
+	if err != nil {
+		return err
+	}
+
+	q := query[createTripQueryKey]
+	_, err = r.conn(ctx).NamedExecContext(ctx, q, row)
+
+	return err
+}
+
+func (r *TelemetryRepo) UpdateTrip(ctx context.Context, t telemetry.Trip) error {
+	row, err := newTripRow(t)
+	if err != nil {
+		return err
+	}
+
+	q := query[updateTripQueryKey]
+	res, err := r.conn(ctx).NamedExecContext(ctx, q, row)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *TelemetryRepo) GetTrip(ctx context.Context, id uuid.UUID) (telemetry.Trip, error) {
+	return r.getTrip(ctx, query[getTripQueryKey], id)
+}
+
+func (r *TelemetryRepo) GetActiveTrip(ctx context.Context, scooterID uuid.UUID) (telemetry.Trip, error) {
+	return r.getTrip(ctx, query[getActiveTripQueryKey], scooterID)
+}
+
+func (r *TelemetryRepo) FindTrips(ctx context.Context, qry telemetry.TripQuery) ([]telemetry.Trip, error) {
+	q := query[findTripsQueryKey]
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"scooter_id": nullUUID(qry.ScooterID),
+		"client_id":  qry.ClientID,
+		"from":       nullTime(qry.From),
+		"to":         nullTime(qry.To),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []telemetry.Trip
+	for rows.Next() {
+		var row tripRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, err
+		}
+
+		t, err := row.trip()
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, t)
+	}
+
+	return trips, rows.Err()
+}
+
+func (r *TelemetryRepo) getTrip(ctx context.Context, q string, arg interface{}) (telemetry.Trip, error) {
+	var row tripRow
+	err := r.conn(ctx).GetContext(ctx, &row, q, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return telemetry.Trip{}, telemetry.ErrNotFound
+	}
+
+	if err != nil {
+		return telemetry.Trip{}, err
+	}
+
+	return row.trip()
+}
+
+// checkAffected turns an update that matched no rows into ErrNotFound.
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return telemetry.ErrNotFound
+	}
+
+	return nil
+}
+
+func nullUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+
+	return id
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
@@ -0,0 +1,197 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// zoneRow is the zones table representation of a telemetry.Zone. The polygon
+// is exchanged with PostGIS as a GeoJSON geometry.
+type zoneRow struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	Rule      string    `db:"rule"`
+	MaxSpeed  float64   `db:"max_speed"`
+	Active    bool      `db:"active"`
+	Area      string    `db:"area"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+func newZoneRow(z telemetry.Zone) (zoneRow, error) {
+	ring := make([][2]float64, 0, len(z.Polygon)+1)
+	for _, p := range z.Polygon {
+		ring = append(ring, [2]float64{p.Lng, p.Lat})
+	}
+
+	// PostGIS requires closed rings.
+	if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+
+	area, err := json.Marshal(geoJSONPolygon{Type: "Polygon", Coordinates: [][][2]float64{ring}})
+	if err != nil {
+		return zoneRow{}, err
+	}
+
+	return zoneRow{
+		ID:        z.ID,
+		Name:      z.Name,
+		Rule:      string(z.Rule),
+		MaxSpeed:  z.MaxSpeed,
+		Active:    z.Active,
+		Area:      string(area),
+		CreatedAt: z.CreatedAt,
+		UpdatedAt: z.UpdatedAt,
+	}, nil
+}
+
+func (row zoneRow) zone() (telemetry.Zone, error) {
+	var area geoJSONPolygon
+	err := json.Unmarshal([]byte(row.Area), &area)
+	if err != nil {
+		return telemetry.Zone{}, err
+	}
+
+	z := telemetry.Zone{
+		ID:        row.ID,
+		Name:      row.Name,
+		Rule:      telemetry.ZoneRule(row.Rule),
+		MaxSpeed:  row.MaxSpeed,
+		Active:    row.Active,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+
+	if len(area.Coordinates) > 0 {
+		for _, c := range area.Coordinates[0] {
+			z.Polygon = append(z.Polygon, telemetry.Point{Lat: c[1], Lng: c[0]})
+		}
+	}
+
+	return z, nil
+}
+
+func (r *TelemetryRepo) CreateZone(ctx context.Context, z telemetry.Zone) error {
+	row, err := newZoneRow(z)
+	if err != nil {
+		return err
+	}
+
+	q := query[createZoneQueryKey]
+	_, err = r.conn(ctx).NamedExecContext(ctx, q, row)
+
+	return err
+}
+
+func (r *TelemetryRepo) UpdateZone(ctx context.Context, z telemetry.Zone) error {
+	row, err := newZoneRow(z)
+	if err != nil {
+		return err
+	}
+
+	q := query[updateZoneQueryKey]
+	res, err := r.conn(ctx).NamedExecContext(ctx, q, row)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *TelemetryRepo) GetZone(ctx context.Context, id uuid.UUID) (telemetry.Zone, error) {
+	var row zoneRow
+	q := query[getZoneQueryKey]
+	err := r.conn(ctx).GetContext(ctx, &row, q, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return telemetry.Zone{}, telemetry.ErrNotFound
+	}
+
+	if err != nil {
+		return telemetry.Zone{}, err
+	}
+
+	return row.zone()
+}
+
+func (r *TelemetryRepo) DeleteZone(ctx context.Context, id uuid.UUID) error {
+	q := query[deleteZoneQueryKey]
+	res, err := r.conn(ctx).ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res)
+}
+
+func (r *TelemetryRepo) ListZones(ctx context.Context) ([]telemetry.Zone, error) {
+	return r.selectZones(ctx, query[listZonesQueryKey])
+}
+
+// FindZonesContaining relies on the GiST index on zones.area to find the
+// active zones covering the point.
+func (r *TelemetryRepo) FindZonesContaining(ctx context.Context, p telemetry.Point) ([]telemetry.Zone, error) {
+	return r.selectZones(ctx, query[findZonesContainingQueryKey], p.Lng, p.Lat)
+}
+
+func (r *TelemetryRepo) CreateViolation(ctx context.Context, v telemetry.Violation) error {
+	q := query[createViolationQueryKey]
+	_, err := r.conn(ctx).NamedExecContext(ctx, q, v)
+
+	return err
+}
+
+func (r *TelemetryRepo) FindViolations(ctx context.Context, qry telemetry.ViolationQuery) ([]telemetry.Violation, error) {
+	q := query[findViolationsQueryKey]
+	rows, err := sqlx.NamedQueryContext(ctx, r.conn(ctx), q, map[string]interface{}{
+		"scooter_id": nullUUID(qry.ScooterID),
+		"trip_id":    nullUUID(qry.TripID),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var violations []telemetry.Violation
+	for rows.Next() {
+		var v telemetry.Violation
+		if err := rows.StructScan(&v); err != nil {
+			return nil, err
+		}
+		violations = append(violations, v)
+	}
+
+	return violations, rows.Err()
+}
+
+func (r *TelemetryRepo) selectZones(ctx context.Context, q string, args ...interface{}) ([]telemetry.Zone, error) {
+	var rows []zoneRow
+	err := r.conn(ctx).SelectContext(ctx, &rows, q, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]telemetry.Zone, 0, len(rows))
+	for _, row := range rows {
+		z, err := row.zone()
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+
+	return zones, nil
+}
@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// MaxBatchSize caps the number of events accepted in a single batch.
+const MaxBatchSize = 500
+
+// maxBatchLine is the longest NDJSON line read from a batch.
+const maxBatchLine = 64 * 1024
+
+var ErrInvalidBatch = newError(KindInvalid, "invalid_batch", "invalid event batch")
+
+// BatchItem is the outcome of one event of a batch. Err is set when the event
+// was rejected, otherwise Result holds what ReportEvent returned.
+type BatchItem struct {
+	Result EventResult
+	Err    error
+}
+
+// batchResult is the per-event entry of a batch response. Status is the HTTP
+// status the event would have got if it had been reported on its own, so the
+// device knows which events are worth retrying.
+type batchResult struct {
+	Index  int          `json:"index"`
+	Status int          `json:"status"`
+	Code   string       `json:"code,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Result *EventResult `json:"result,omitempty"`
+}
+
+func newBatchResult(index int, item BatchItem) batchResult {
+	if item.Err != nil {
+		return batchResult{
+			Index:  index,
+			Status: errStatus(item.Err),
+			Code:   ErrorCode(item.Err),
+			Error:  ErrorMessage(item.Err),
+		}
+	}
+
+	res := item.Result
+	return batchResult{Index: index, Status: http.StatusCreated, Result: &res}
+}
+
+// batchEvent is an event read from a batch request body, or the reason it
+// could not be decoded.
+type batchEvent struct {
+	event Event
+	err   error
+}
+
+func decodeBatchEvent(raw []byte) batchEvent {
+	var e Event
+	err := json.Unmarshal(raw, &e)
+	if err != nil {
+		return batchEvent{err: fmt.Errorf("%w: %v", ErrInvalidEvent, err)}
+	}
+
+	return batchEvent{event: e}
+}
+
+// readEventBatch reads the events of a batch request. The body is either a
+// JSON array or, with an NDJSON content type, one event per line. An item
+// that cannot be decoded is kept with its error so that the rest of the batch
+// goes through.
+func readEventBatch(r *http.Request) ([]batchEvent, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var batch []batchEvent
+	var err error
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson":
+		batch, err = readNDJSONBatch(r.Body)
+	default:
+		batch, err = readJSONBatch(r.Body)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("%w: empty batch", ErrInvalidBatch)
+	}
+
+	return batch, nil
+}
+
+func readJSONBatch(r io.Reader) ([]batchEvent, error) {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBatch, err)
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return nil, fmt.Errorf("%w: expected an array of events", ErrInvalidBatch)
+	}
+
+	var batch []batchEvent
+	for dec.More() {
+		if len(batch) == MaxBatchSize {
+			return nil, fmt.Errorf("%w: more than %d events", ErrInvalidBatch, MaxBatchSize)
+		}
+
+		var raw json.RawMessage
+		err = dec.Decode(&raw)
+		if err != nil {
+			// The array itself is malformed, there is no next item to
+			// resume from.
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBatch, err)
+		}
+
+		batch = append(batch, decodeBatchEvent(raw))
+	}
+
+	return batch, nil
+}
+
+func readNDJSONBatch(r io.Reader) ([]batchEvent, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), maxBatchLine)
+
+	var batch []batchEvent
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if len(batch) == MaxBatchSize {
+			return nil, fmt.Errorf("%w: more than %d events", ErrInvalidBatch, MaxBatchSize)
+		}
+
+		batch = append(batch, decodeBatchEvent(line))
+	}
+
+	err := sc.Err()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBatch, err)
+	}
+
+	return batch, nil
+}
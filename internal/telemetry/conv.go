@@ -1,40 +1,323 @@
 package telemetry
 
 import (
+	"encoding/base64"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+var ErrInvalidCursor = newError(KindInvalid, "invalid_cursor", "invalid cursor")
+
+// NewQuery builds a Query from the area bounds and the optional status,
+// minBattery, limit and cursor query parameters.
 func NewQuery(r *http.Request) (Query, error) {
 	q := r.URL.Query()
-	var qry Query
+	qry := Query{Status: Status(q.Get("status"))}
 	var err error
 
-	qry.Area.MinLat, err = parseFloat(q.Get("minLat"))
+	qry.Area, err = areaParams(q)
 	if err != nil {
 		return qry, err
 	}
 
-	qry.Area.MinLng, err = parseFloat(q.Get("minLng"))
+	qry.MinBattery, err = intParam(q, "minBattery", 0)
 	if err != nil {
 		return qry, err
 	}
 
-	qry.Area.MaxLat, err = parseFloat(q.Get("maxLat"))
+	qry.Limit, err = intParam(q, "limit", DefaultPageSize)
 	if err != nil {
 		return qry, err
 	}
 
-	qry.Area.MaxLng, err = parseFloat(q.Get("maxLng"))
+	if v := q.Get("cursor"); v != "" {
+		qry.After, err = parseCursor(v)
+		if err != nil {
+			return qry, err
+		}
+	}
+
+	return qry, nil
+}
+
+// NewGridQuery builds a GridQuery from the area bounds and the optional
+// cellSize (degrees) and status query parameters.
+func NewGridQuery(r *http.Request) (GridQuery, error) {
+	q := r.URL.Query()
+	qry := GridQuery{CellSize: DefaultGridCellSize, Status: Status(q.Get("status"))}
+	var err error
+
+	qry.Area, err = areaParams(q)
 	if err != nil {
 		return qry, err
 	}
 
-	qry.Status = Status(q.Get("status"))
+	if q.Has("cellSize") {
+		qry.CellSize, err = floatParam(q, "cellSize")
+		if err != nil {
+			return qry, err
+		}
+	}
 
 	return qry, nil
 }
 
+// NewStreamQuery builds a StreamQuery from the area bounds and the
+// Last-Event-ID header, or the lastEventId query parameter for clients that
+// cannot set headers.
+func NewStreamQuery(r *http.Request) (StreamQuery, error) {
+	q := r.URL.Query()
+
+	area, err := areaParams(q)
+	if err != nil {
+		return StreamQuery{}, err
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = q.Get("lastEventId")
+	}
+
+	return StreamQuery{Area: area, LastEventID: lastID}, nil
+}
+
+// areaParams reads the minLat, minLng, maxLat and maxLng query parameters.
+func areaParams(q url.Values) (Area, error) {
+	var a Area
+	var err error
+
+	a.MinLat, err = floatParam(q, "minLat")
+	if err != nil {
+		return a, err
+	}
+
+	a.MinLng, err = floatParam(q, "minLng")
+	if err != nil {
+		return a, err
+	}
+
+	a.MaxLat, err = floatParam(q, "maxLat")
+	if err != nil {
+		return a, err
+	}
+
+	a.MaxLng, err = floatParam(q, "maxLng")
+	if err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
+
+// encodeCursor returns the opaque cursor of the page following id.
+func encodeCursor(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+func parseCursor(cursor string) (uuid.UUID, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return uuid.Nil, ErrInvalidCursor
+	}
+
+	id, err := uuid.FromBytes(b)
+	if err != nil {
+		return uuid.Nil, ErrInvalidCursor
+	}
+
+	return id, nil
+}
+
+// ifMatchVersion returns the scooter version required by the If-Match header,
+// or 0 when any version will do. A tag that is not a scooter version can never
+// match and fails with ErrVersionConflict.
+func ifMatchVersion(r *http.Request) (int, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || v < 1 || !strings.HasPrefix(tag, `"`) {
+		return 0, fmt.Errorf("%w: unknown entity tag %s", ErrVersionConflict, tag)
+	}
+
+	return v, nil
+}
+
+// NewNearbyQuery builds a NearbyQuery from the lat and lng query parameters
+// and the optional radius (meters), limit, status and minBattery ones.
+func NewNearbyQuery(r *http.Request) (NearbyQuery, error) {
+	q := r.URL.Query()
+	qry := NearbyQuery{
+		Radius: DefaultNearbyRadius,
+		Status: Status(q.Get("status")),
+	}
+	var err error
+
+	qry.Center.Lat, err = floatParam(q, "lat")
+	if err != nil {
+		return qry, err
+	}
+
+	qry.Center.Lng, err = floatParam(q, "lng")
+	if err != nil {
+		return qry, err
+	}
+
+	if q.Has("radius") {
+		qry.Radius, err = floatParam(q, "radius")
+		if err != nil {
+			return qry, err
+		}
+	}
+
+	qry.Limit, err = intParam(q, "limit", DefaultNearbyLimit)
+	if err != nil {
+		return qry, err
+	}
+
+	qry.MinBattery, err = intParam(q, "minBattery", 0)
+	if err != nil {
+		return qry, err
+	}
+
+	return qry, nil
+}
+
+// NewTripQuery builds a TripQuery from the optional scooterId, clientId, from
+// and to (RFC 3339) query parameters.
+func NewTripQuery(r *http.Request) (TripQuery, error) {
+	q := r.URL.Query()
+	qry := TripQuery{ClientID: q.Get("clientId")}
+	var err error
+
+	if v := q.Get("scooterId"); v != "" {
+		qry.ScooterID, err = uuid.Parse(v)
+		if err != nil {
+			return qry, invalidField(ErrInvalidQuery, "scooterId", "must be a UUID")
+		}
+	}
+
+	qry.From, err = timeParam(q, "from")
+	if err != nil {
+		return qry, err
+	}
+
+	qry.To, err = timeParam(q, "to")
+	if err != nil {
+		return qry, err
+	}
+
+	return qry, nil
+}
+
+// NewEventQuery builds an EventQuery from the optional type, from and to
+// (RFC 3339) and limit query parameters. The scooter is set by the caller.
+func NewEventQuery(r *http.Request) (EventQuery, error) {
+	q := r.URL.Query()
+	qry := EventQuery{Type: EventType(q.Get("type"))}
+	var err error
+
+	qry.From, err = timeParam(q, "from")
+	if err != nil {
+		return qry, err
+	}
+
+	qry.To, err = timeParam(q, "to")
+	if err != nil {
+		return qry, err
+	}
+
+	qry.Limit, err = intParam(q, "limit", DefaultEventLimit)
+	if err != nil {
+		return qry, err
+	}
+
+	return qry, nil
+}
+
+// NewTrackQuery builds a TrackQuery from the optional from and to (RFC 3339)
+// and tolerance (meters) query parameters. The scooter is set by the caller.
+func NewTrackQuery(r *http.Request) (TrackQuery, error) {
+	q := r.URL.Query()
+	var qry TrackQuery
+	var err error
+
+	qry.From, err = timeParam(q, "from")
+	if err != nil {
+		return qry, err
+	}
+
+	qry.To, err = timeParam(q, "to")
+	if err != nil {
+		return qry, err
+	}
+
+	if q.Has("tolerance") {
+		qry.Tolerance, err = floatParam(q, "tolerance")
+		if err != nil {
+			return qry, err
+		}
+	}
+
+	return qry, nil
+}
+
+// floatParam parses a required numeric query parameter. NaN and infinities
+// are rejected, they would slip through range checks.
+func floatParam(q url.Values, name string) (float64, error) {
+	v, err := parseFloat(q.Get(name))
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, invalidField(ErrInvalidQuery, name, "must be a number")
+	}
+
+	return v, nil
+}
+
+// intParam parses an optional integer query parameter, def is returned when
+// it is not set.
+func intParam(q url.Values, name string, def int) (int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, invalidField(ErrInvalidQuery, name, "must be an integer")
+	}
+
+	return n, nil
+}
+
+// timeParam parses an optional RFC 3339 query parameter.
+func timeParam(q url.Values, name string) (time.Time, error) {
+	t, err := parseTime(q.Get(name))
+	if err != nil {
+		return time.Time{}, invalidField(ErrInvalidQuery, name, "must be an RFC 3339 timestamp")
+	}
+
+	return t, nil
+}
+
 func parseFloat(val string) (float64, error) {
 	return strconv.ParseFloat(val, 64)
 }
+
+// parseTime parses an optional RFC 3339 timestamp, an empty value yields the
+// zero time.
+func parseTime(val string) (time.Time, error) {
+	if val == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, val)
+}
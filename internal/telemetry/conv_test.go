@@ -1,14 +1,18 @@
 package telemetry_test
 
 import (
+	"encoding/base64"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
 )
 
 func TestAreaFromQuery(t *testing.T) {
+	cursorID := uuid.New()
 	tests := []struct {
 		name    string
 		params  map[string]string
@@ -32,9 +36,77 @@ func TestAreaFromQuery(t *testing.T) {
 					MaxLng: 18.0,
 				},
 				Status: "free",
+				Limit:  telemetry.DefaultPageSize,
 			},
 			wantErr: false,
 		},
+		{
+			name: "min battery",
+			params: map[string]string{
+				"minLat":     "51.0",
+				"minLng":     "17.0",
+				"maxLat":     "52.0",
+				"maxLng":     "18.0",
+				"status":     "free",
+				"minBattery": "40",
+			},
+			want: telemetry.Query{
+				Area: telemetry.Area{
+					MinLat: 51.0,
+					MinLng: 17.0,
+					MaxLat: 52.0,
+					MaxLng: 18.0,
+				},
+				Status:     "free",
+				MinBattery: 40,
+				Limit:      telemetry.DefaultPageSize,
+			},
+			wantErr: false,
+		},
+		{
+			name: "limit and cursor",
+			params: map[string]string{
+				"minLat": "51.0",
+				"minLng": "17.0",
+				"maxLat": "52.0",
+				"maxLng": "18.0",
+				"limit":  "20",
+				"cursor": base64.RawURLEncoding.EncodeToString(cursorID[:]),
+			},
+			want: telemetry.Query{
+				Area: telemetry.Area{
+					MinLat: 51.0,
+					MinLng: 17.0,
+					MaxLat: 52.0,
+					MaxLng: 18.0,
+				},
+				Limit: 20,
+				After: cursorID,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid cursor",
+			params: map[string]string{
+				"minLat": "51.0",
+				"minLng": "17.0",
+				"maxLat": "52.0",
+				"maxLng": "18.0",
+				"cursor": "bm90LWEtdXVpZA",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid min battery",
+			params: map[string]string{
+				"minLat":     "51.0",
+				"minLng":     "17.0",
+				"maxLat":     "52.0",
+				"maxLng":     "18.0",
+				"minBattery": "full",
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing param",
 			params: map[string]string{
@@ -76,3 +148,59 @@ func TestAreaFromQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestNewTripQuery(t *testing.T) {
+	scooterID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    telemetry.TripQuery
+		wantErr bool
+	}{
+		{
+			name:   "no filters",
+			params: map[string]string{},
+			want:   telemetry.TripQuery{},
+		},
+		{
+			name: "all filters",
+			params: map[string]string{
+				"scooterId": scooterID.String(),
+				"clientId":  "rider-1",
+				"from":      "2024-01-01T00:00:00Z",
+				"to":        "2024-01-02T00:00:00Z",
+			},
+			want: telemetry.TripQuery{ScooterID: scooterID, ClientID: "rider-1", From: from, To: to},
+		},
+		{
+			name:    "invalid scooter id",
+			params:  map[string]string{"scooterId": "not-a-uuid"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			params:  map[string]string{"to": "tomorrow"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := url.Values{}
+			for k, v := range tt.params {
+				u.Set(k, v)
+			}
+			r := &http.Request{URL: &url.URL{RawQuery: u.Encode()}}
+			qry, err := telemetry.NewTripQuery(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && qry != tt.want {
+				t.Errorf("expected query: %+v, got: %+v", tt.want, qry)
+			}
+		})
+	}
+}
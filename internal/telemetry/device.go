@@ -0,0 +1,232 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// deviceMaxMessage is the largest message accepted from a device.
+	deviceMaxMessage = 64 * 1024
+	// devicePingInterval is how often the server pings a device.
+	devicePingInterval = 30 * time.Second
+	// devicePongWait is how long a silent device is kept connected. It must
+	// be longer than the ping interval.
+	devicePongWait = 75 * time.Second
+	// deviceWriteWait is how long a write to a device may take.
+	deviceWriteWait = 10 * time.Second
+	// deviceOutbox is how many replies may wait to be written before the
+	// channel stops reading from the device.
+	deviceOutbox = 32
+)
+
+// Device channel message types.
+const (
+	deviceMsgEvent   = "event"
+	deviceMsgAck     = "ack"
+	deviceMsgError   = "error"
+	deviceMsgCommand = "command"
+)
+
+// CommandSetStatus asks a device to move to the given status, e.g. to lock
+// when an operator takes the scooter out of service.
+const CommandSetStatus = "set_status"
+
+var ErrInvalidMessage = newError(KindInvalid, "invalid_message", "invalid device message")
+
+var deviceUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// deviceMessage is a message sent by a device. Ref is chosen by the device
+// and echoed in the reply so that it can match acknowledgements.
+type deviceMessage struct {
+	Type  string `json:"type"`
+	Ref   string `json:"ref,omitempty"`
+	Event Event  `json:"event"`
+}
+
+// deviceReply is a message sent to a device: the acknowledgement or the error
+// of one of its messages, or a command.
+type deviceReply struct {
+	Type    string         `json:"type"`
+	Ref     string         `json:"ref,omitempty"`
+	Result  *EventResult   `json:"result,omitempty"`
+	Status  int            `json:"status,omitempty"`
+	Code    string         `json:"code,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Command *DeviceCommand `json:"command,omitempty"`
+}
+
+// DeviceCommand is an instruction pushed to a device after its scooter was
+// changed server side.
+type DeviceCommand struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+}
+
+func newDeviceError(ref string, err error) deviceReply {
+	return deviceReply{
+		Type:   deviceMsgError,
+		Ref:    ref,
+		Status: errStatus(err),
+		Code:   ErrorCode(err),
+		Error:  ErrorMessage(err),
+	}
+}
+
+// deviceChannel is the connection of a scooter device. Events read from the
+// device are reported one at a time, in the order they were sent, and every
+// one gets a reply. Replies and commands are written by a single writer;
+// when the device does not read them the channel stops reading its events.
+type deviceChannel struct {
+	service   Service
+	conn      *websocket.Conn
+	scooterID uuid.UUID
+	origin    string
+	status    Status
+	sub       *Subscription
+	out       chan deviceReply
+}
+
+// serve runs the channel until the device disconnects, ctx is done or the
+// device falls too far behind on commands.
+func (c *deviceChannel) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.write(ctx)
+		cancel()
+		// Unblock the reader.
+		_ = c.conn.Close()
+	}()
+
+	c.read(ctx)
+	cancel()
+	<-done
+}
+
+func (c *deviceChannel) read(ctx context.Context) {
+	c.conn.SetReadLimit(deviceMaxMessage)
+	_ = c.conn.SetReadDeadline(time.Now().Add(devicePongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(devicePongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("device %s: read error: %v", c.scooterID, err)
+			}
+			return
+		}
+
+		_ = c.conn.SetReadDeadline(time.Now().Add(devicePongWait))
+
+		select {
+		case c.out <- c.handle(ctx, data):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handle reports the event carried by a device message. Events default to
+// the scooter of the channel and cannot be sent for another one.
+func (c *deviceChannel) handle(ctx context.Context, data []byte) deviceReply {
+	var msg deviceMessage
+	err := json.Unmarshal(data, &msg)
+	if err != nil {
+		return newDeviceError("", fmt.Errorf("%w: %v", ErrInvalidMessage, err))
+	}
+
+	if msg.Type != deviceMsgEvent {
+		return newDeviceError(msg.Ref, invalidField(ErrInvalidMessage, "type", fmt.Sprintf("unknown message type %q", msg.Type)))
+	}
+
+	e := msg.Event
+	if e.ScooterID == uuid.Nil {
+		e.ScooterID = c.scooterID
+	}
+
+	if e.ScooterID != c.scooterID {
+		return newDeviceError(msg.Ref, invalidField(ErrInvalidEvent, "scooterId", "must be the scooter of the channel"))
+	}
+
+	res, err := c.service.ReportEvent(ctx, e)
+	if err != nil {
+		if KindOf(err) == KindInternal {
+			log.Printf("device %s: event error: %v", c.scooterID, err)
+		}
+		return newDeviceError(msg.Ref, err)
+	}
+
+	return deviceReply{Type: deviceMsgAck, Ref: msg.Ref, Result: &res}
+}
+
+func (c *deviceChannel) write(ctx context.Context) {
+	ping := time.NewTicker(devicePingInterval)
+	defer ping.Stop()
+
+	// The device learns the status it has to be in as soon as it connects.
+	err := c.send(c.command(c.status))
+
+	for err == nil {
+		select {
+		case <-ctx.Done():
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+			_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(deviceWriteWait))
+			return
+
+		case reply := <-c.out:
+			if reply.Result != nil {
+				c.status = reply.Result.Status
+			}
+			err = c.send(reply)
+
+		case change, ok := <-c.sub.Changes():
+			if !ok {
+				if c.sub.Lagged() {
+					log.Printf("device %s: dropped, not reading commands", c.scooterID)
+				}
+				return
+			}
+
+			// Changes made through this channel are acknowledged instead.
+			if (c.origin != "" && change.origin == c.origin) || change.Scooter.Status == c.status {
+				continue
+			}
+
+			c.status = change.Scooter.Status
+			err = c.send(c.command(c.status))
+
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(deviceWriteWait))
+		}
+	}
+
+	if !errors.Is(err, websocket.ErrCloseSent) {
+		log.Printf("device %s: write error: %v", c.scooterID, err)
+	}
+}
+
+func (c *deviceChannel) command(status Status) deviceReply {
+	return deviceReply{Type: deviceMsgCommand, Command: &DeviceCommand{Name: CommandSetStatus, Status: status}}
+}
+
+func (c *deviceChannel) send(reply deviceReply) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(deviceWriteWait))
+	return c.conn.WriteJSON(reply)
+}
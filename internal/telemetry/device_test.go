@@ -0,0 +1,111 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/repo/mem"
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// deviceReply mirrors the messages the server sends on a device channel.
+type deviceReply struct {
+	Type    string                   `json:"type"`
+	Ref     string                   `json:"ref"`
+	Result  *telemetry.EventResult   `json:"result"`
+	Status  int                      `json:"status"`
+	Code    string                   `json:"code"`
+	Command *telemetry.DeviceCommand `json:"command"`
+}
+
+func TestDeviceChannel(t *testing.T) {
+	scooter := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooter)))
+	srv := httptest.NewServer(telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/scooters/" + scooter.ID.String() + "/channel"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the channel to require an API key, got %v", err)
+	}
+
+	header := http.Header{"X-API-Key": {"key"}, "X-Client-ID": {"scooter-device"}}
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+
+	read := func() deviceReply {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var r deviceReply
+		if err := conn.ReadJSON(&r); err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		return r
+	}
+
+	if r := read(); r.Type != "command" || r.Command == nil || r.Command.Status != telemetry.StatusFree {
+		t.Fatalf("expected the current status as first command, got %+v", r)
+	}
+
+	send := func(msg string) {
+		t.Helper()
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	send(`{"type":"event","ref":"1","event":{"type":"battery","battery":60}}`)
+	send(`{"type":"event","ref":"2","event":{"type":"location","lat":45.43,"lng":-75.70}}`)
+	send(`{"type":"event","ref":"3","event":{"scooterId":"` + uuid.NewString() + `","type":"battery","battery":50}}`)
+	send(`{"type":"hello","ref":"4"}`)
+
+	want := []struct {
+		ref, typ, code string
+		status         int
+	}{
+		{"1", "ack", "", 0},
+		{"2", "error", "invalid_transition", http.StatusConflict},
+		{"3", "error", "invalid_event", http.StatusBadRequest},
+		{"4", "error", "invalid_message", http.StatusBadRequest},
+	}
+
+	for _, w := range want {
+		r := read()
+		if r.Ref != w.ref || r.Type != w.typ || r.Code != w.code || r.Status != w.status {
+			t.Errorf("reply %s = %+v, want %s %s %d", w.ref, r, w.typ, w.code, w.status)
+		}
+	}
+
+	operator := telemetry.WithOperator(context.Background())
+	_, err = svc.ChangeStatus(operator, telemetry.StatusChange{ScooterID: scooter.ID, Status: telemetry.StatusMaintenance})
+	if err != nil {
+		t.Fatalf("ChangeStatus() error = %v", err)
+	}
+
+	if r := read(); r.Type != "command" || r.Command.Name != telemetry.CommandSetStatus || r.Command.Status != telemetry.StatusMaintenance {
+		t.Errorf("expected a set_status maintenance command, got %+v", r)
+	}
+
+	got, _ := svc.GetScooter(context.Background(), scooter.ID)
+	if got.Battery != 60 {
+		t.Errorf("expected the device events to reach the service, got battery %d", got.Battery)
+	}
+
+	var raw json.RawMessage
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if err := conn.ReadJSON(&raw); err == nil {
+		t.Errorf("expected no more messages, got %s", raw)
+	}
+}
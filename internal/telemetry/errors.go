@@ -0,0 +1,137 @@
+package telemetry
+
+import "errors"
+
+// ErrorKind classifies domain errors by the way callers should react to them.
+// Each kind maps to a single HTTP status.
+type ErrorKind int
+
+const (
+	// KindInternal is the kind of any error that is not a domain error, e.g. a
+	// storage failure. Its details are never exposed to clients.
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPrecondition
+	KindUnsupported
+)
+
+// Error is a domain error with a stable, machine readable code clients can
+// branch on. The package sentinel errors are *Error values; wrap them with
+// fmt.Errorf or invalidField to add details.
+type Error struct {
+	Kind ErrorKind
+	Code string
+	msg  string
+}
+
+func newError(kind ErrorKind, code, msg string) *Error {
+	return &Error{Kind: kind, Code: code, msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// CodeInternal is the code of errors that are not domain errors.
+const CodeInternal = "internal"
+
+// ErrInvalidBody is returned when a request body cannot be decoded.
+var ErrInvalidBody = newError(KindInvalid, "invalid_body", "unmarshalable request body")
+
+// FieldError points at the request field that made a request invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// fieldError annotates a domain error with the field it is about.
+type fieldError struct {
+	err   error
+	field FieldError
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error() + ": " + e.field.Message
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// invalidField wraps err, typically ErrInvalidQuery or another invalid input
+// sentinel, with the offending field. Its message reads like
+// fmt.Errorf("%w: msg", err).
+func invalidField(err error, field, msg string) error {
+	return &fieldError{err: err, field: FieldError{Field: field, Message: msg}}
+}
+
+// domainError returns the domain error wrapped in err, if any.
+func domainError(err error) (*Error, bool) {
+	var de *Error
+	ok := errors.As(err, &de)
+	return de, ok
+}
+
+// KindOf returns the kind of err, KindInternal when it is not a domain error.
+func KindOf(err error) ErrorKind {
+	if de, ok := domainError(err); ok {
+		return de.Kind
+	}
+
+	return KindInternal
+}
+
+// ErrorCode returns the stable code of err, CodeInternal when it is not a
+// domain error.
+func ErrorCode(err error) string {
+	if de, ok := domainError(err); ok {
+		return de.Code
+	}
+
+	return CodeInternal
+}
+
+// ErrorMessage returns the message of err that is safe to show to clients.
+// Errors that are not domain errors may carry driver or system details and
+// are replaced by a generic message.
+func ErrorMessage(err error) string {
+	if KindOf(err) == KindInternal {
+		return "internal error"
+	}
+
+	return err.Error()
+}
+
+// FieldErrors returns the field errors wrapped in err, joined errors
+// included.
+func FieldErrors(err error) []FieldError {
+	var fields []FieldError
+
+	var walk func(error)
+	walk = func(err error) {
+		if fe, ok := err.(*fieldError); ok {
+			fields = append(fields, fe.field)
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			if inner := e.Unwrap(); inner != nil {
+				walk(inner)
+			}
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		}
+	}
+
+	if err != nil {
+		walk(err)
+	}
+
+	return fields
+}
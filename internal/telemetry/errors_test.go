@@ -0,0 +1,86 @@
+package telemetry_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind telemetry.ErrorKind
+		wantCode string
+		wantMsg  string
+	}{
+		{
+			name:     "sentinel",
+			err:      telemetry.ErrNotFound,
+			wantKind: telemetry.KindNotFound,
+			wantCode: "not_found",
+			wantMsg:  "not found",
+		},
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("%w: trip_start on occupied scooter", telemetry.ErrInvalidTransition),
+			wantKind: telemetry.KindConflict,
+			wantCode: "invalid_transition",
+			wantMsg:  "invalid status transition: trip_start on occupied scooter",
+		},
+		{
+			name:     "validation",
+			err:      telemetry.DefaultValidator(telemetry.OpChangeStatus, telemetry.StatusChange{ScooterID: [16]byte{1}, Status: "broken"}),
+			wantKind: telemetry.KindInvalid,
+			wantCode: "invalid_status",
+			wantMsg:  `invalid status: unknown status "broken"`,
+		},
+		{
+			name:     "internal",
+			err:      errors.New(`pq: relation "scooters" does not exist`),
+			wantKind: telemetry.KindInternal,
+			wantCode: telemetry.CodeInternal,
+			wantMsg:  "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := telemetry.KindOf(tt.err); got != tt.wantKind {
+				t.Errorf("KindOf() = %v, want %v", got, tt.wantKind)
+			}
+
+			if got := telemetry.ErrorCode(tt.err); got != tt.wantCode {
+				t.Errorf("ErrorCode() = %q, want %q", got, tt.wantCode)
+			}
+
+			if got := telemetry.ErrorMessage(tt.err); got != tt.wantMsg {
+				t.Errorf("ErrorMessage() = %q, want %q", got, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	battery := 120
+	err := telemetry.DefaultValidator(telemetry.OpReportEvent, telemetry.Event{
+		ScooterID: [16]byte{1},
+		Type:      telemetry.EventBattery,
+		Battery:   &battery,
+	})
+
+	fields := telemetry.FieldErrors(fmt.Errorf("report: %w", err))
+	if len(fields) != 1 || fields[0].Field != "battery" || fields[0].Message != "invalid battery level" {
+		t.Errorf("unexpected field errors: %+v", fields)
+	}
+
+	if !errors.Is(err, telemetry.ErrInvalidEvent) {
+		t.Errorf("expected field error to wrap ErrInvalidEvent, got %v", err)
+	}
+
+	if fields := telemetry.FieldErrors(telemetry.ErrNotFound); fields != nil {
+		t.Errorf("expected no field errors, got %+v", fields)
+	}
+}
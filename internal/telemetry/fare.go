@@ -0,0 +1,215 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+var (
+	ErrNoTariff      = newError(KindInternal, "no_tariff", "no tariff for trip")
+	ErrInvalidTariff = newError(KindInternal, "invalid_tariff", "invalid tariff")
+)
+
+// Pricer computes the fare of a closed trip.
+type Pricer interface {
+	Price(t Trip) (Fare, error)
+}
+
+// Fare is the price of a trip broken down by component. Amounts are in minor
+// currency units (e.g. cents) to avoid rounding drift.
+type Fare struct {
+	Tariff         string `json:"tariff"`
+	Currency       string `json:"currency"`
+	UnlockFee      int64  `json:"unlockFee"`
+	Minutes        int    `json:"minutes"`
+	TimeCharge     int64  `json:"timeCharge"`
+	DistanceCharge int64  `json:"distanceCharge"`
+	Surcharge      int64  `json:"surcharge"`
+	Total          int64  `json:"total"`
+}
+
+// Tariff holds the rates applied to trips started inside its area. A tariff
+// without an area applies anywhere and is used as a fallback.
+type Tariff struct {
+	City       string      `json:"city"`
+	Currency   string      `json:"currency"`
+	Timezone   string      `json:"timezone"`
+	Area       *Area       `json:"area,omitempty"`
+	UnlockFee  int64       `json:"unlockFee"`
+	PerMinute  int64       `json:"perMinute"`
+	PerKm      int64       `json:"perKm"`
+	Surcharges []Surcharge `json:"surcharges,omitempty"`
+
+	loc *time.Location
+}
+
+// Surcharge raises the fare by Percent for trips started between From and To
+// local time (HH:MM). Windows may wrap around midnight, e.g. 22:00 to 06:00.
+type Surcharge struct {
+	Name    string `json:"name"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Percent int64  `json:"percent"`
+
+	from, to int // minutes since midnight
+}
+
+// Tariffs is a Pricer backed by a list of per-city tariffs. The first tariff
+// whose area contains the trip start wins.
+type Tariffs []Tariff
+
+// LoadTariffs reads and validates a JSON array of tariffs from path.
+func LoadTariffs(path string) (Tariffs, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tt Tariffs
+	err = json.Unmarshal(b, &tt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTariff, err)
+	}
+
+	for i := range tt {
+		err = tt[i].init()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tt, nil
+}
+
+// Price picks the tariff for the trip start point and applies it.
+func (tt Tariffs) Price(t Trip) (Fare, error) {
+	var fallback *Tariff
+
+	for i := range tt {
+		tariff := &tt[i]
+		if tariff.Area == nil {
+			if fallback == nil {
+				fallback = tariff
+			}
+			continue
+		}
+
+		if tariff.Area.Contains(t.Start) {
+			return tariff.Price(t), nil
+		}
+	}
+
+	if fallback != nil {
+		return fallback.Price(t), nil
+	}
+
+	return Fare{}, ErrNoTariff
+}
+
+// Price computes the fare of a closed trip. Every started minute is charged
+// and surcharges are applied on the subtotal according to the local start time.
+func (tariff *Tariff) Price(t Trip) Fare {
+	var d time.Duration
+	if t.EndedAt != nil {
+		d = t.EndedAt.Sub(t.StartedAt)
+	}
+
+	minutes := int(math.Ceil(d.Minutes()))
+	if minutes < 0 {
+		minutes = 0
+	}
+
+	f := Fare{
+		Tariff:         tariff.City,
+		Currency:       tariff.Currency,
+		UnlockFee:      tariff.UnlockFee,
+		Minutes:        minutes,
+		TimeCharge:     int64(minutes) * tariff.PerMinute,
+		DistanceCharge: int64(math.Round(t.Distance * float64(tariff.PerKm) / 1000)),
+	}
+
+	subtotal := f.UnlockFee + f.TimeCharge + f.DistanceCharge
+
+	start := t.StartedAt
+	if tariff.loc != nil {
+		start = start.In(tariff.loc)
+	}
+
+	for _, s := range tariff.Surcharges {
+		if s.applies(start) {
+			f.Surcharge += int64(math.Round(float64(subtotal*s.Percent) / 100))
+		}
+	}
+
+	f.Total = subtotal + f.Surcharge
+
+	return f
+}
+
+func (tariff *Tariff) init() error {
+	if tariff.City == "" || tariff.Currency == "" {
+		return fmt.Errorf("%w: city and currency are required", ErrInvalidTariff)
+	}
+
+	if tariff.UnlockFee < 0 || tariff.PerMinute < 0 || tariff.PerKm < 0 {
+		return fmt.Errorf("%w: %s: negative rate", ErrInvalidTariff, tariff.City)
+	}
+
+	if tariff.Timezone != "" {
+		loc, err := time.LoadLocation(tariff.Timezone)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidTariff, tariff.City, err)
+		}
+		tariff.loc = loc
+	}
+
+	for i := range tariff.Surcharges {
+		err := tariff.Surcharges[i].init()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidTariff, tariff.City, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Surcharge) init() error {
+	var err error
+
+	s.from, err = clockMinutes(s.From)
+	if err != nil {
+		return err
+	}
+
+	s.to, err = clockMinutes(s.To)
+	if err != nil {
+		return err
+	}
+
+	if s.Percent < 0 {
+		return fmt.Errorf("surcharge %q: negative percent", s.Name)
+	}
+
+	return nil
+}
+
+func (s Surcharge) applies(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if s.from <= s.to {
+		return m >= s.from && m < s.to
+	}
+
+	return m >= s.from || m < s.to
+}
+
+func clockMinutes(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", hhmm)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
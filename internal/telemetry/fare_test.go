@@ -0,0 +1,133 @@
+package telemetry_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+const tariffsJSON = `[
+  {
+    "city": "ottawa",
+    "currency": "CAD",
+    "timezone": "America/Toronto",
+    "area": {"minLat": 45.2, "minLng": -76.0, "maxLat": 45.6, "maxLng": -75.4},
+    "unlockFee": 100,
+    "perMinute": 35,
+    "perKm": 50,
+    "surcharges": [{"name": "night", "from": "22:00", "to": "06:00", "percent": 20}]
+  },
+  {
+    "city": "default",
+    "currency": "CAD",
+    "unlockFee": 150,
+    "perMinute": 40
+  }
+]`
+
+func loadTestTariffs(t *testing.T, content string) (telemetry.Tariffs, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tariffs.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write tariffs: %v", err)
+	}
+
+	return telemetry.LoadTariffs(path)
+}
+
+func TestTariffs_Price(t *testing.T) {
+	tariffs, err := loadTestTariffs(t, tariffsJSON)
+	if err != nil {
+		t.Fatalf("LoadTariffs() error = %v", err)
+	}
+
+	toronto, err := time.LoadLocation("America/Toronto")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	ottawa := telemetry.Point{Lat: 45.42, Lng: -75.69}
+	trip := func(start telemetry.Point, at time.Time, d time.Duration, meters float64) telemetry.Trip {
+		end := at.Add(d)
+		return telemetry.Trip{Start: start, StartedAt: at, EndedAt: &end, Distance: meters}
+	}
+
+	tests := []struct {
+		name string
+		trip telemetry.Trip
+		want telemetry.Fare
+	}{
+		{
+			name: "city tariff by day",
+			trip: trip(ottawa, time.Date(2024, 5, 1, 14, 0, 0, 0, toronto), 10*time.Minute+time.Second, 2500),
+			want: telemetry.Fare{Tariff: "ottawa", Currency: "CAD", UnlockFee: 100, Minutes: 11, TimeCharge: 385, DistanceCharge: 125, Total: 610},
+		},
+		{
+			name: "city tariff at night",
+			trip: trip(ottawa, time.Date(2024, 5, 1, 23, 30, 0, 0, toronto), 5*time.Minute, 1000),
+			want: telemetry.Fare{Tariff: "ottawa", Currency: "CAD", UnlockFee: 100, Minutes: 5, TimeCharge: 175, DistanceCharge: 50, Surcharge: 65, Total: 390},
+		},
+		{
+			name: "night surcharge uses local time",
+			trip: trip(ottawa, time.Date(2024, 5, 2, 3, 0, 0, 0, time.UTC), 5*time.Minute, 1000),
+			want: telemetry.Fare{Tariff: "ottawa", Currency: "CAD", UnlockFee: 100, Minutes: 5, TimeCharge: 175, DistanceCharge: 50, Surcharge: 65, Total: 390},
+		},
+		{
+			name: "fallback tariff outside city areas",
+			trip: trip(telemetry.Point{Lat: 10, Lng: 10}, time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC), 2*time.Minute, 800),
+			want: telemetry.Fare{Tariff: "default", Currency: "CAD", UnlockFee: 150, Minutes: 2, TimeCharge: 80, Total: 230},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tariffs.Price(tt.trip)
+			if err != nil {
+				t.Fatalf("Price() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Price() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTariffs_NoTariff(t *testing.T) {
+	tariffs, err := loadTestTariffs(t, `[{"city": "ottawa", "currency": "CAD", "area": {"minLat": 45, "minLng": -76, "maxLat": 46, "maxLng": -75}}]`)
+	if err != nil {
+		t.Fatalf("LoadTariffs() error = %v", err)
+	}
+
+	_, err = tariffs.Price(telemetry.Trip{Start: telemetry.Point{Lat: 10, Lng: 10}})
+	if !errors.Is(err, telemetry.ErrNoTariff) {
+		t.Errorf("expected ErrNoTariff, got %v", err)
+	}
+}
+
+func TestLoadTariffs_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed json", `{`},
+		{"missing currency", `[{"city": "ottawa"}]`},
+		{"negative rate", `[{"city": "ottawa", "currency": "CAD", "perMinute": -1}]`},
+		{"unknown timezone", `[{"city": "ottawa", "currency": "CAD", "timezone": "Mars/Olympus"}]`},
+		{"bad surcharge window", `[{"city": "ottawa", "currency": "CAD", "surcharges": [{"from": "25:00", "to": "06:00", "percent": 10}]}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestTariffs(t, tt.content)
+			if !errors.Is(err, telemetry.ErrInvalidTariff) {
+				t.Errorf("expected ErrInvalidTariff, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package telemetry
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FleetFormat is a bulk fleet import/export file format.
+type FleetFormat string
+
+const (
+	FormatCSV     FleetFormat = "csv"
+	FormatGeoJSON FleetFormat = "geojson"
+)
+
+var (
+	ErrUnsupportedFormat = newError(KindUnsupported, "unsupported_format", "unsupported fleet format")
+	ErrInvalidImport     = newError(KindInvalid, "invalid_import", "invalid fleet import")
+	ErrInvalidRow        = newError(KindInvalid, "invalid_row", "invalid row")
+)
+
+// csvHeader is the column layout written on export. On import columns are
+// matched by name and only lat and lng are required.
+var csvHeader = []string{"id", "status", "lat", "lng", "battery", "updatedAt"}
+
+// FleetRow is a scooter as read from an import file. A row without ID creates
+// a new scooter; status and battery may be left empty to keep the current
+// values of an existing one.
+type FleetRow struct {
+	Row     int
+	ID      uuid.UUID
+	Status  Status
+	Lat     float64
+	Lng     float64
+	Battery *int
+}
+
+// RowError reports why a single import row was rejected.
+type RowError struct {
+	Row   int    `json:"row"`
+	ID    string `json:"id,omitempty"`
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// ImportResult summarizes a bulk import. Rejected rows do not stop the
+// import, they are listed in Errors.
+type ImportResult struct {
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+}
+
+// fail records a rejected row.
+func (res *ImportResult) fail(row FleetRow, err error) {
+	re := RowError{Row: row.Row, Code: ErrorCode(err), Error: ErrorMessage(err)}
+	if row.ID != uuid.Nil {
+		re.ID = row.ID.String()
+	}
+
+	res.Failed++
+	res.Errors = append(res.Errors, re)
+}
+
+// FleetReader streams rows from an import file. Read returns io.EOF at the
+// end of the input. A *rowError is returned for a malformed row the reader
+// can skip; any other error aborts the import.
+type FleetReader interface {
+	Read() (FleetRow, error)
+}
+
+// rowError wraps a per-row parsing failure.
+type rowError struct {
+	row FleetRow
+	err error
+}
+
+// newRowError reports a malformed row. Parser errors are classified as
+// ErrInvalidRow so that they are shown to the importer.
+func newRowError(row FleetRow, err error) *rowError {
+	if KindOf(err) == KindInternal {
+		err = fmt.Errorf("%w: %v", ErrInvalidRow, err)
+	}
+
+	return &rowError{row: row, err: err}
+}
+
+func (e *rowError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rowError) Unwrap() error {
+	return e.err
+}
+
+// NewFleetReader returns a streaming reader for the given format.
+func NewFleetReader(format FleetFormat, r io.Reader) (FleetReader, error) {
+	switch format {
+	case FormatCSV:
+		return &csvFleetReader{r: csv.NewReader(r)}, nil
+	case FormatGeoJSON:
+		return &geoJSONFleetReader{dec: json.NewDecoder(r)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// WriteFleet writes scooters to w in the given format.
+func WriteFleet(format FleetFormat, w io.Writer, scooters []Scooter) error {
+	switch format {
+	case FormatCSV:
+		return writeFleetCSV(w, scooters)
+	case FormatGeoJSON:
+		return json.NewEncoder(w).Encode(newScooterCollection(scooters))
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+type csvFleetReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func (cr *csvFleetReader) Read() (FleetRow, error) {
+	if cr.columns == nil {
+		err := cr.readHeader()
+		if err != nil {
+			return FleetRow{}, err
+		}
+	}
+
+	record, err := cr.r.Read()
+	cr.row++
+	row := FleetRow{Row: cr.row}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return row, newRowError(row, parseErr.Err)
+	}
+
+	if err != nil {
+		return row, err
+	}
+
+	err = cr.parse(record, &row)
+	if err != nil {
+		return row, newRowError(row, err)
+	}
+
+	return row, nil
+}
+
+func (cr *csvFleetReader) readHeader() error {
+	cr.r.FieldsPerRecord = -1
+	cr.r.TrimLeadingSpace = true
+
+	header, err := cr.r.Read()
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: empty file", ErrInvalidImport)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	cr.row = 1
+	cr.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		cr.columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{"lat", "lng"} {
+		if _, ok := cr.columns[required]; !ok {
+			return fmt.Errorf("%w: missing %q column", ErrInvalidImport, required)
+		}
+	}
+
+	return nil
+}
+
+func (cr *csvFleetReader) parse(record []string, row *FleetRow) error {
+	field := func(name string) string {
+		i, ok := cr.columns[strings.ToLower(name)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var err error
+
+	if v := field("id"); v != "" {
+		row.ID, err = uuid.Parse(v)
+		if err != nil {
+			return ErrInvalidID
+		}
+	}
+
+	row.Status = Status(field("status"))
+
+	row.Lat, err = parseFloat(field("lat"))
+	if err != nil {
+		return fmt.Errorf("%w: invalid lat", ErrInvalidScooter)
+	}
+
+	row.Lng, err = parseFloat(field("lng"))
+	if err != nil {
+		return fmt.Errorf("%w: invalid lng", ErrInvalidScooter)
+	}
+
+	if v := field("battery"); v != "" {
+		battery, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%w: invalid battery level", ErrInvalidScooter)
+		}
+		row.Battery = &battery
+	}
+
+	return nil
+}
+
+func writeFleetCSV(w io.Writer, scooters []Scooter) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range scooters {
+		err = cw.Write([]string{
+			s.ID.String(),
+			string(s.Status),
+			strconv.FormatFloat(s.Lat, 'f', -1, 64),
+			strconv.FormatFloat(s.Lng, 'f', -1, 64),
+			strconv.Itoa(s.Battery),
+			s.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// geoJSONFleetReader walks a FeatureCollection one feature at a time so large
+// files are never fully loaded in memory.
+type geoJSONFleetReader struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+	row     int
+}
+
+func (gr *geoJSONFleetReader) Read() (FleetRow, error) {
+	if gr.done {
+		return FleetRow{}, io.EOF
+	}
+
+	if !gr.started {
+		err := gr.seekFeatures()
+		if err != nil {
+			return FleetRow{}, err
+		}
+		gr.started = true
+	}
+
+	if !gr.dec.More() {
+		gr.done = true
+		return FleetRow{}, io.EOF
+	}
+
+	var raw json.RawMessage
+	err := gr.dec.Decode(&raw)
+	if err != nil {
+		return FleetRow{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	gr.row++
+	row := FleetRow{Row: gr.row}
+
+	var f scooterFeature
+	err = json.Unmarshal(raw, &f)
+	if err != nil {
+		return row, newRowError(row, err)
+	}
+
+	row, err = f.fleetRow(gr.row)
+	if err != nil {
+		return row, newRowError(row, err)
+	}
+
+	return row, nil
+}
+
+// seekFeatures advances the decoder to the first element of the features
+// array, skipping any other member of the collection.
+func (gr *geoJSONFleetReader) seekFeatures() error {
+	err := gr.expectDelim('{')
+	if err != nil {
+		return err
+	}
+
+	for gr.dec.More() {
+		tok, err := gr.dec.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+
+		if key, _ := tok.(string); key == "features" {
+			return gr.expectDelim('[')
+		}
+
+		var skip json.RawMessage
+		err = gr.dec.Decode(&skip)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+	}
+
+	return fmt.Errorf("%w: missing features", ErrInvalidImport)
+}
+
+func (gr *geoJSONFleetReader) expectDelim(want json.Delim) error {
+	tok, err := gr.dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("%w: expected %q", ErrInvalidImport, want)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+// readAll drains a fleet reader, collecting rows and row-level errors apart.
+func readAll(t *testing.T, fr telemetry.FleetReader) ([]telemetry.FleetRow, []int, error) {
+	t.Helper()
+
+	var rows []telemetry.FleetRow
+	var failed []int
+
+	for {
+		row, err := fr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, failed, nil
+		}
+
+		if errors.Is(err, telemetry.ErrInvalidImport) {
+			return rows, failed, err
+		}
+
+		if err != nil {
+			failed = append(failed, row.Row)
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+func TestFleetReader_CSV(t *testing.T) {
+	id := uuid.New()
+	input := strings.Join([]string{
+		"Battery, lng, lat, id, status",
+		"80, -75.69, 45.42, " + id.String() + ", maintenance",
+		"50, -75.70, 45.43, ,",
+		"x, -75.70, 45.43, ,",
+		"50, -75.70, north, ,",
+		"50, -75.70, 45.43, not-a-uuid,",
+		", -75.71, 45.44, " + id.String() + ",",
+	}, "\n")
+
+	fr, err := telemetry.NewFleetReader(telemetry.FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewFleetReader() error = %v", err)
+	}
+
+	rows, failed, err := readAll(t, fr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []int{4, 5, 6}; !equalInts(failed, want) {
+		t.Errorf("failed rows = %v, want %v", failed, want)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Row != 2 || first.ID != id || first.Status != telemetry.StatusMaintenance ||
+		first.Lat != 45.42 || first.Lng != -75.69 || first.Battery == nil || *first.Battery != 80 {
+		t.Errorf("unexpected first row: %+v", first)
+	}
+
+	if rows[1].ID != uuid.Nil || rows[1].Status != "" {
+		t.Errorf("expected row without id and status, got %+v", rows[1])
+	}
+
+	if rows[2].Battery != nil {
+		t.Errorf("expected empty battery to be left unset, got %v", *rows[2].Battery)
+	}
+}
+
+func TestFleetReader_CSVMissingColumn(t *testing.T) {
+	fr, _ := telemetry.NewFleetReader(telemetry.FormatCSV, strings.NewReader("id,lat\n"))
+
+	_, err := fr.Read()
+	if !errors.Is(err, telemetry.ErrInvalidImport) {
+		t.Errorf("expected ErrInvalidImport, got %v", err)
+	}
+}
+
+func TestFleetReader_GeoJSON(t *testing.T) {
+	id := uuid.New()
+	input := `{
+  "type": "FeatureCollection",
+  "name": "fleet",
+  "crs": {"type": "name", "properties": {"name": "EPSG:4326"}},
+  "features": [
+    {"type": "Feature", "id": "` + id.String() + `", "geometry": {"type": "Point", "coordinates": [-75.69, 45.42]}, "properties": {"status": "offline", "battery": 60}},
+    {"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[-75.69, 45.42], [-75.7, 45.43]]}, "properties": {}},
+    {"type": "Feature", "geometry": {"type": "Point", "coordinates": [-75.69, 45.42]}, "properties": {"battery": "full"}},
+    {"type": "Feature", "geometry": {"type": "Point", "coordinates": [-73.56, 45.50]}, "properties": {"battery": 90}}
+  ]
+}`
+
+	fr, err := telemetry.NewFleetReader(telemetry.FormatGeoJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewFleetReader() error = %v", err)
+	}
+
+	rows, failed, err := readAll(t, fr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []int{2, 3}; !equalInts(failed, want) {
+		t.Errorf("failed rows = %v, want %v", failed, want)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	if rows[0].ID != id || rows[0].Status != telemetry.StatusOffline || rows[0].Lat != 45.42 || rows[0].Lng != -75.69 {
+		t.Errorf("unexpected first row: %+v", rows[0])
+	}
+
+	if rows[1].Row != 4 || rows[1].Lat != 45.50 || *rows[1].Battery != 90 {
+		t.Errorf("unexpected last row: %+v", rows[1])
+	}
+}
+
+func TestFleetReader_GeoJSONMalformed(t *testing.T) {
+	for _, input := range []string{`[]`, `{"type": "FeatureCollection"}`, `{"features": [{"type": "Feature",`} {
+		fr, _ := telemetry.NewFleetReader(telemetry.FormatGeoJSON, strings.NewReader(input))
+
+		_, _, err := readAll(t, fr)
+		if !errors.Is(err, telemetry.ErrInvalidImport) {
+			t.Errorf("%s: expected ErrInvalidImport, got %v", input, err)
+		}
+	}
+}
+
+func TestWriteFleet_RoundTrip(t *testing.T) {
+	scooters := []telemetry.Scooter{
+		{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80, UpdatedAt: time.Now()},
+		{ID: uuid.New(), Status: telemetry.StatusDecommissioned, Lat: 45.5, Lng: -73.56, Battery: 0, UpdatedAt: time.Now()},
+	}
+
+	for _, format := range []telemetry.FleetFormat{telemetry.FormatCSV, telemetry.FormatGeoJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := telemetry.WriteFleet(format, &buf, scooters); err != nil {
+				t.Fatalf("WriteFleet() error = %v", err)
+			}
+
+			fr, _ := telemetry.NewFleetReader(format, &buf)
+			rows, failed, err := readAll(t, fr)
+			if err != nil || len(failed) > 0 {
+				t.Fatalf("read back: failed rows %v, err %v", failed, err)
+			}
+
+			if len(rows) != len(scooters) {
+				t.Fatalf("expected %d rows, got %d", len(scooters), len(rows))
+			}
+
+			for i, s := range scooters {
+				got := rows[i]
+				if got.ID != s.ID || got.Status != s.Status || got.Lat != s.Lat || got.Lng != s.Lng ||
+					got.Battery == nil || *got.Battery != s.Battery {
+					t.Errorf("row %d = %+v, want %+v", i, got, s)
+				}
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,67 @@
+package telemetry
+
+import "math"
+
+// earthRadius is the mean Earth radius in meters.
+const earthRadius = 6371008.8
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Distance returns the great-circle distance in meters between two points
+// using the haversine formula.
+func Distance(a, b Point) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+const (
+	// DefaultNearbyRadius is the search radius in meters when none is given.
+	DefaultNearbyRadius = 500
+	// MaxNearbyRadius caps the search radius in meters.
+	MaxNearbyRadius = 5000
+	// DefaultNearbyLimit is the number of results when no limit is given.
+	DefaultNearbyLimit = 10
+	// MaxNearbyLimit caps the number of results.
+	MaxNearbyLimit = 100
+)
+
+// NearbyQuery looks for the scooters closest to Center within Radius meters.
+// Status and MinBattery filter like in Query.
+type NearbyQuery struct {
+	Center     Point
+	Radius     float64
+	Limit      int
+	Status     Status
+	MinBattery int
+}
+
+// Match reports whether the scooter passes the status and battery filters.
+// An empty status matches any status but decommissioned.
+func (q NearbyQuery) Match(s Scooter) bool {
+	if q.Status == "" && s.Status == StatusDecommissioned {
+		return false
+	}
+
+	if q.Status != "" && s.Status != q.Status {
+		return false
+	}
+
+	return s.Battery >= q.MinBattery
+}
+
+// NearbyScooter is a scooter with its great-circle distance in meters to the
+// query center.
+type NearbyScooter struct {
+	Scooter
+	Distance float64 `json:"distance"`
+}
@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GeoJSON (RFC 7946) object types.
+const (
+	geoJSONFeatureCollection = "FeatureCollection"
+	geoJSONFeature           = "Feature"
+	geoJSONPoint             = "Point"
+	geoJSONLineString        = "LineString"
+)
+
+// pointGeometry is a GeoJSON Point. Coordinates are [lng, lat].
+type pointGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+func newPointGeometry(p Point) pointGeometry {
+	return pointGeometry{Type: geoJSONPoint, Coordinates: []float64{p.Lng, p.Lat}}
+}
+
+func (g pointGeometry) point() (Point, error) {
+	if g.Type != geoJSONPoint || len(g.Coordinates) < 2 {
+		return Point{}, errors.New("geometry must be a Point")
+	}
+
+	return Point{Lat: g.Coordinates[1], Lng: g.Coordinates[0]}, nil
+}
+
+// scooterProperties are the GeoJSON feature properties of a scooter. Battery
+// and status are optional on import so that existing scooters keep theirs.
+type scooterProperties struct {
+	Status    Status     `json:"status,omitempty"`
+	Battery   *int       `json:"battery,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// scooterFeature is a scooter as a GeoJSON Point feature.
+type scooterFeature struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id,omitempty"`
+	Geometry   pointGeometry     `json:"geometry"`
+	Properties scooterProperties `json:"properties"`
+}
+
+// scooterCollection is a GeoJSON FeatureCollection of scooters. Next is a
+// foreign member carrying the pagination cursor of search results.
+type scooterCollection struct {
+	Type     string           `json:"type"`
+	Features []scooterFeature `json:"features"`
+	Next     string           `json:"next,omitempty"`
+}
+
+func newScooterFeature(s Scooter) scooterFeature {
+	battery := s.Battery
+	updatedAt := s.UpdatedAt
+
+	return scooterFeature{
+		Type:     geoJSONFeature,
+		ID:       s.ID.String(),
+		Geometry: newPointGeometry(s.Location()),
+		Properties: scooterProperties{
+			Status:    s.Status,
+			Battery:   &battery,
+			UpdatedAt: &updatedAt,
+		},
+	}
+}
+
+func newScooterCollection(scooters []Scooter) scooterCollection {
+	fc := scooterCollection{
+		Type:     geoJSONFeatureCollection,
+		Features: make([]scooterFeature, 0, len(scooters)),
+	}
+
+	for _, s := range scooters {
+		fc.Features = append(fc.Features, newScooterFeature(s))
+	}
+
+	return fc
+}
+
+// fleetRow turns an imported feature into a fleet row.
+func (f scooterFeature) fleetRow(row int) (FleetRow, error) {
+	fr := FleetRow{
+		Row:     row,
+		Status:  f.Properties.Status,
+		Battery: f.Properties.Battery,
+	}
+
+	if f.Type != geoJSONFeature {
+		return fr, fmt.Errorf("unexpected type %q", f.Type)
+	}
+
+	if f.ID != "" {
+		id, err := uuid.Parse(f.ID)
+		if err != nil {
+			return fr, ErrInvalidID
+		}
+		fr.ID = id
+	}
+
+	p, err := f.Geometry.point()
+	if err != nil {
+		return fr, err
+	}
+	fr.Lat, fr.Lng = p.Lat, p.Lng
+
+	return fr, nil
+}
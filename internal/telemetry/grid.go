@@ -0,0 +1,120 @@
+package telemetry
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultGridCellSize is the side of the grid cells in degrees when none
+	// is given, roughly 1 km of latitude.
+	DefaultGridCellSize = 0.01
+	// MaxGridCells caps the number of cells of a grid, so that a tiny cell
+	// size over a large area is rejected instead of computed.
+	MaxGridCells = 10000
+)
+
+// GridQuery buckets the scooters in Area into square cells of CellSize
+// degrees, starting at the south-west corner of the area. Status filters like
+// in Query.
+type GridQuery struct {
+	Area     Area
+	CellSize float64
+	Status   Status
+}
+
+// Dimensions returns the number of rows (latitude) and columns (longitude)
+// of the grid. Cells on the north and east edges may stick out of the area.
+func (q GridQuery) Dimensions() (rows, cols int) {
+	r, c := q.span()
+	return max(int(r), 1), max(int(c), 1)
+}
+
+// span returns the grid dimensions as floats, so that they can be checked
+// before conversion. Areas a whole number of cells wide do not get an extra
+// row or column from rounding errors.
+func (q GridQuery) span() (rows, cols float64) {
+	const epsilon = 1e-9
+	rows = math.Ceil((q.Area.MaxLat-q.Area.MinLat)/q.CellSize - epsilon)
+	cols = math.Ceil((q.Area.MaxLng-q.Area.MinLng)/q.CellSize - epsilon)
+
+	return rows, cols
+}
+
+// Cell returns the row and column of the cell containing p. Points on the
+// north and east borders of the area fall in the last row and column.
+func (q GridQuery) Cell(p Point) (row, col int) {
+	rows, cols := q.Dimensions()
+	row = int(math.Floor((p.Lat - q.Area.MinLat) / q.CellSize))
+	col = int(math.Floor((p.Lng - q.Area.MinLng) / q.CellSize))
+
+	return min(row, rows-1), min(col, cols-1)
+}
+
+// Bounds returns the area covered by a cell.
+func (q GridQuery) Bounds(row, col int) Area {
+	minLat := q.Area.MinLat + float64(row)*q.CellSize
+	minLng := q.Area.MinLng + float64(col)*q.CellSize
+
+	return Area{MinLat: minLat, MinLng: minLng, MaxLat: minLat + q.CellSize, MaxLng: minLng + q.CellSize}
+}
+
+// CellCount is the number of scooters with a status in a grid cell, as
+// aggregated by the repo.
+type CellCount struct {
+	Row    int
+	Col    int
+	Status Status
+	Count  int
+}
+
+// GridCell is a non-empty cell of a density grid with its scooter counts,
+// in total and by status.
+type GridCell struct {
+	Row    int            `json:"row"`
+	Col    int            `json:"col"`
+	Bounds Area           `json:"bounds"`
+	Total  int            `json:"total"`
+	Counts map[Status]int `json:"counts"`
+}
+
+// DensityGrid is the scooter density over an area. Only the cells holding
+// scooters are listed, ordered by row and column.
+type DensityGrid struct {
+	CellSize float64    `json:"cellSize"`
+	Rows     int        `json:"rows"`
+	Cols     int        `json:"cols"`
+	Cells    []GridCell `json:"cells"`
+}
+
+// NewDensityGrid assembles the cells of a grid from the repo counts.
+func NewDensityGrid(qry GridQuery, counts []CellCount) DensityGrid {
+	rows, cols := qry.Dimensions()
+	grid := DensityGrid{CellSize: qry.CellSize, Rows: rows, Cols: cols, Cells: []GridCell{}}
+
+	cells := make(map[[2]int]*GridCell)
+	for _, c := range counts {
+		key := [2]int{c.Row, c.Col}
+		cell, ok := cells[key]
+		if !ok {
+			cell = &GridCell{Row: c.Row, Col: c.Col, Bounds: qry.Bounds(c.Row, c.Col), Counts: make(map[Status]int)}
+			cells[key] = cell
+		}
+
+		cell.Counts[c.Status] += c.Count
+		cell.Total += c.Count
+	}
+
+	for _, cell := range cells {
+		grid.Cells = append(grid.Cells, *cell)
+	}
+
+	sort.Slice(grid.Cells, func(i, j int) bool {
+		if grid.Cells[i].Row != grid.Cells[j].Row {
+			return grid.Cells[i].Row < grid.Cells[j].Row
+		}
+		return grid.Cells[i].Col < grid.Cells[j].Col
+	})
+
+	return grid
+}
@@ -0,0 +1,38 @@
+package telemetry_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+func TestNewDensityGrid(t *testing.T) {
+	qry := telemetry.GridQuery{
+		Area:     telemetry.Area{MinLat: 45.0, MinLng: -75.0, MaxLat: 45.2, MaxLng: -74.75},
+		CellSize: 0.1,
+	}
+
+	if rows, cols := qry.Dimensions(); rows != 2 || cols != 3 {
+		t.Errorf("Dimensions() = %d, %d, want 2, 3", rows, cols)
+	}
+
+	grid := telemetry.NewDensityGrid(qry, []telemetry.CellCount{
+		{Row: 1, Col: 2, Status: telemetry.StatusFree, Count: 1},
+		{Row: 0, Col: 1, Status: telemetry.StatusFree, Count: 3},
+		{Row: 0, Col: 1, Status: telemetry.StatusLowBattery, Count: 2},
+	})
+
+	if len(grid.Cells) != 2 || grid.Rows != 2 || grid.Cols != 3 {
+		t.Fatalf("unexpected grid: %+v", grid)
+	}
+
+	cell := grid.Cells[0]
+	if cell.Row != 0 || cell.Col != 1 || cell.Total != 5 || cell.Counts[telemetry.StatusLowBattery] != 2 {
+		t.Errorf("expected the first cell with its counts merged, got %+v", cell)
+	}
+
+	if math.Abs(cell.Bounds.MinLng+74.9) > 1e-9 || math.Abs(cell.Bounds.MaxLat-45.1) > 1e-9 {
+		t.Errorf("unexpected cell bounds: %+v", cell.Bounds)
+	}
+}
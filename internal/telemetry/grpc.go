@@ -0,0 +1,305 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/adrianpk/rida/internal/telemetry/telemetrypb"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the ErrorInfo details of gRPC errors.
+const errorDomain = "rida"
+
+// kindCode maps every error kind to its gRPC status code.
+var kindCode = map[ErrorKind]codes.Code{
+	KindInternal:     codes.Internal,
+	KindInvalid:      codes.InvalidArgument,
+	KindUnauthorized: codes.Unauthenticated,
+	KindForbidden:    codes.PermissionDenied,
+	KindNotFound:     codes.NotFound,
+	KindConflict:     codes.FailedPrecondition,
+	KindPrecondition: codes.Aborted,
+	KindUnsupported:  codes.InvalidArgument,
+}
+
+// errCode maps an error to its gRPC status code.
+func errCode(err error) codes.Code {
+	return kindCode[KindOf(err)]
+}
+
+// GRPCServer exposes the service over gRPC, see
+// proto/telemetry/v1/telemetry.proto. Requests go through the same service,
+// and so the same validation, as the HTTP ones.
+type GRPCServer struct {
+	telemetrypb.UnimplementedTelemetryServiceServer
+	service Service
+}
+
+// NewGRPCServer returns a gRPC server serving svc to the callers holding one
+// of the client or operator API keys.
+func NewGRPCServer(svc Service, validAPIKeys []string, operatorAPIKeys ...string) *grpc.Server {
+	auth := grpcAuth{keys: validAPIKeys, operatorKeys: operatorAPIKeys}
+
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.unary),
+		grpc.StreamInterceptor(auth.stream),
+	)
+
+	telemetrypb.RegisterTelemetryServiceServer(srv, &GRPCServer{service: svc})
+	return srv
+}
+
+func (g *GRPCServer) GetScooter(ctx context.Context, req *telemetrypb.GetScooterRequest) (*telemetrypb.Scooter, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, grpcError(ctx, invalidField(ErrInvalidID, "id", "must be a UUID"))
+	}
+
+	scooter, err := g.service.GetScooter(ctx, id)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return pbScooter(scooter), nil
+}
+
+func (g *GRPCServer) FindScooters(ctx context.Context, req *telemetrypb.FindScootersRequest) (*telemetrypb.FindScootersResponse, error) {
+	qry, err := queryFromPB(req)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	page, err := g.service.FindScooters(ctx, qry)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	resp := &telemetrypb.FindScootersResponse{NextCursor: page.Next}
+	for _, s := range page.Scooters {
+		resp.Scooters = append(resp.Scooters, pbScooter(s))
+	}
+
+	return resp, nil
+}
+
+func (g *GRPCServer) ReportEvent(ctx context.Context, req *telemetrypb.ReportEventRequest) (*telemetrypb.EventResult, error) {
+	event, err := eventFromPB(req.GetEvent())
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	key, err := idempotencyKeyMetadata(ctx)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	if key != "" {
+		ctx = WithIdempotencyKey(ctx, key)
+	}
+
+	res, err := g.service.ReportEvent(ctx, event)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return pbEventResult(res), nil
+}
+
+// ReportEvents reports the streamed events one at a time, in the order they
+// are received. Their outcomes are kept for the response, so a stream takes
+// at most MaxBatchSize events, like a batch; past that the call fails with
+// ResourceExhausted and the events already applied stay applied. Otherwise
+// only a broken stream fails the call; rejected events get their error in
+// the response.
+func (g *GRPCServer) ReportEvents(stream telemetrypb.TelemetryService_ReportEventsServer) error {
+	ctx := stream.Context()
+	resp := &telemetrypb.ReportEventsResponse{}
+
+	for i := 0; ; i++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if i == MaxBatchSize {
+			return status.Errorf(codes.ResourceExhausted, "more than %d events", MaxBatchSize)
+		}
+
+		resp.Outcomes = append(resp.Outcomes, g.reportStreamed(ctx, i, req))
+	}
+}
+
+func (g *GRPCServer) reportStreamed(ctx context.Context, index int, req *telemetrypb.ReportEventRequest) *telemetrypb.EventOutcome {
+	event, err := eventFromPB(req.GetEvent())
+	if err == nil {
+		var res EventResult
+		res, err = g.service.ReportEvent(ctx, event)
+		if err == nil {
+			return &telemetrypb.EventOutcome{Index: int32(index), Result: pbEventResult(res)}
+		}
+	}
+
+	if KindOf(err) == KindInternal {
+		logGRPCError(ctx, err)
+	}
+
+	return &telemetrypb.EventOutcome{
+		Index:   int32(index),
+		Code:    int32(errCode(err)),
+		Reason:  ErrorCode(err),
+		Message: ErrorMessage(err),
+	}
+}
+
+// grpcError logs err and turns it into a gRPC status error.
+func grpcError(ctx context.Context, err error) error {
+	logGRPCError(ctx, err)
+	return grpcStatus(ctx, err)
+}
+
+// grpcStatus turns err into a gRPC status error. The stable error code and
+// the request ID travel in an ErrorInfo detail and the invalid fields, if
+// any, in a BadRequest detail.
+func grpcStatus(ctx context.Context, err error) error {
+	requestID, _ := RequestID(ctx)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   ErrorCode(err),
+		Domain:   errorDomain,
+		Metadata: map[string]string{"requestId": requestID},
+	}}
+
+	if fields := FieldErrors(err); len(fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+		}
+		details = append(details, br)
+	}
+
+	st := status.New(errCode(err), ErrorMessage(err))
+	if withDetails, derr := st.WithDetails(details...); derr == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
+
+func logGRPCError(ctx context.Context, err error) {
+	clientID, _ := ClientID(ctx)
+	prefix := "grpc error:"
+
+	if clientID != "" {
+		prefix = fmt.Sprintf("[%s] grpc error:", clientID)
+	}
+
+	if requestID, ok := RequestID(ctx); ok {
+		prefix = fmt.Sprintf("%s request %s:", prefix, requestID)
+	}
+
+	log.Printf("%s %v", prefix, err)
+}
+
+// grpcAuth authenticates gRPC calls as AuthMiddleware does HTTP requests,
+// reading the x-api-key and x-client-id metadata. Calls are tagged with the
+// x-request-id sent by the caller, or a new one, which is sent back in the
+// response header.
+type grpcAuth struct {
+	keys         []string
+	operatorKeys []string
+}
+
+func (a grpcAuth) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a grpcAuth) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a grpcAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	requestID := metadataValue(ctx, "x-request-id")
+	if !isValidRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	ctx = WithRequestID(ctx, requestID)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	apiKey := metadataValue(ctx, "x-api-key")
+	clientID := metadataValue(ctx, "x-client-id")
+
+	operator := apiKey != "" && contains(a.operatorKeys, apiKey)
+	valid := operator || contains(a.keys, apiKey)
+
+	if !valid {
+		log.Printf("auth: invalid API key: %q, client: %q, method: %s", mask(apiKey), clientID, method)
+		return nil, grpcStatus(ctx, ErrInvalidAPIKey)
+	}
+
+	ctx = WithClientID(ctx, clientID)
+	if operator {
+		ctx = WithOperator(ctx)
+	}
+
+	return ctx, nil
+}
+
+// authStream is a server stream carrying the authenticated context.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataValue returns the first value of the incoming metadata key.
+func metadataValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+// idempotencyKeyMetadata reads the idempotency-key metadata, the gRPC
+// counterpart of the Idempotency-Key header.
+func idempotencyKeyMetadata(ctx context.Context) (string, error) {
+	key := metadataValue(ctx, "idempotency-key")
+	if key == "" {
+		return "", nil
+	}
+
+	if len(key) > maxIdempotencyKeyLen || !isPrintable(key) {
+		return "", invalidField(ErrInvalidIdempotencyKey, "idempotency-key", "must be up to 255 printable characters")
+	}
+
+	return key, nil
+}
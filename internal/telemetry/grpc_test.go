@@ -0,0 +1,195 @@
+package telemetry_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/repo/mem"
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/adrianpk/rida/internal/telemetry/telemetrypb"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves svc over an in-memory connection.
+func newGRPCClient(t *testing.T, svc telemetry.Service) telemetrypb.TelemetryServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := telemetry.NewGRPCServer(svc, []string{"key"})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	dial := func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }
+	conn, err := grpc.NewClient("passthrough:///bufnet", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return telemetrypb.NewTelemetryServiceClient(conn)
+}
+
+// errorReason returns the stable error code carried by a gRPC error.
+func errorReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
+
+func TestGRPCServer(t *testing.T) {
+	scooter := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooter)))
+	client := newGRPCClient(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.GetScooter(ctx, &telemetrypb.GetScooterRequest{Id: scooter.ID.String()})
+	if status.Code(err) != codes.Unauthenticated || errorReason(err) != "invalid_api_key" {
+		t.Fatalf("expected calls without an API key to be rejected, got %v", err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", "key", "x-client-id", "rider-1")
+
+	got, err := client.GetScooter(ctx, &telemetrypb.GetScooterRequest{Id: scooter.ID.String()})
+	if err != nil {
+		t.Fatalf("GetScooter() error = %v", err)
+	}
+
+	if got.Status != "free" || got.Battery != 80 {
+		t.Errorf("GetScooter() = %v, want the stored scooter", got)
+	}
+
+	_, err = client.GetScooter(ctx, &telemetrypb.GetScooterRequest{Id: "nope"})
+	if status.Code(err) != codes.InvalidArgument || errorReason(err) != "invalid_scooter_id" {
+		t.Errorf("expected an invalid ID error, got %v", err)
+	}
+
+	area := &telemetrypb.Area{MinLat: 45.3, MinLng: -75.8, MaxLat: 45.5, MaxLng: -75.6}
+	page, err := client.FindScooters(ctx, &telemetrypb.FindScootersRequest{Area: area})
+	if err != nil || len(page.Scooters) != 1 {
+		t.Errorf("FindScooters() = %v, %v, want the scooter", page, err)
+	}
+
+	_, err = client.FindScooters(ctx, &telemetrypb.FindScootersRequest{Area: &telemetrypb.Area{MinLat: 46, MaxLat: 45}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the service validator to reject the area, got %v", err)
+	}
+
+	battery := int32(70)
+	event := &telemetrypb.Event{Id: uuid.NewString(), ScooterId: scooter.ID.String(), Type: "battery", Battery: &battery}
+	res, err := client.ReportEvent(ctx, &telemetrypb.ReportEventRequest{Event: event})
+	if err != nil || res.Replayed {
+		t.Fatalf("ReportEvent() = %v, %v", res, err)
+	}
+
+	res, err = client.ReportEvent(ctx, &telemetrypb.ReportEventRequest{Event: event})
+	if err != nil || !res.Replayed {
+		t.Errorf("expected the retried event to be replayed, got %v, %v", res, err)
+	}
+}
+
+func TestGRPCReportEvents(t *testing.T) {
+	scooter := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooter)))
+	client := newGRPCClient(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", "key", "x-client-id", "rider-1")
+
+	stream, err := client.ReportEvents(ctx)
+	if err != nil {
+		t.Fatalf("ReportEvents() error = %v", err)
+	}
+
+	id := scooter.ID.String()
+	battery := int32(60)
+	events := []*telemetrypb.Event{
+		{ScooterId: id, Type: "trip_start", Lat: 45.42, Lng: -75.69},
+		{ScooterId: "nope", Type: "location"},
+		{ScooterId: id, Type: "location", Lat: 45.43, Lng: -75.70},
+		{ScooterId: id, Type: "battery", Battery: &battery},
+		{ScooterId: id, Type: "trip_start", Lat: 45.43, Lng: -75.70},
+	}
+
+	for _, e := range events {
+		err = stream.Send(&telemetrypb.ReportEventRequest{Event: e})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("CloseAndRecv() error = %v", err)
+	}
+
+	want := []struct {
+		code   codes.Code
+		reason string
+	}{
+		{codes.OK, ""},
+		{codes.InvalidArgument, "invalid_event"},
+		{codes.OK, ""},
+		{codes.OK, ""},
+		{codes.FailedPrecondition, "invalid_transition"},
+	}
+
+	if len(resp.Outcomes) != len(want) {
+		t.Fatalf("expected %d outcomes, got %d", len(want), len(resp.Outcomes))
+	}
+
+	for i, w := range want {
+		o := resp.Outcomes[i]
+		if int(o.Index) != i || codes.Code(o.Code) != w.code || o.Reason != w.reason {
+			t.Errorf("outcome %d = %v, want %s %s", i, o, w.code, w.reason)
+		}
+	}
+
+	got, _ := svc.GetScooter(context.Background(), scooter.ID)
+	if got.Status != telemetry.StatusOccupied || got.Battery != 60 || got.Lat != 45.43 {
+		t.Errorf("expected the streamed events to be applied, got %+v", got)
+	}
+}
+
+func TestGRPCReportEventsLimit(t *testing.T) {
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData()))
+	client := newGRPCClient(t, svc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", "key", "x-client-id", "rider-1")
+
+	stream, err := client.ReportEvents(ctx)
+	if err != nil {
+		t.Fatalf("ReportEvents() error = %v", err)
+	}
+
+	// Send fails once the server gave up on the stream; the status comes
+	// with CloseAndRecv.
+	for i := 0; i <= telemetry.MaxBatchSize; i++ {
+		err = stream.Send(&telemetrypb.ReportEventRequest{Event: &telemetrypb.Event{ScooterId: "nope", Type: "location"}})
+		if err != nil {
+			break
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected streams over %d events to be rejected, got %v", telemetry.MaxBatchSize, err)
+	}
+}
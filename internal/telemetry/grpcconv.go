@@ -0,0 +1,152 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry/telemetrypb"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// queryFromPB builds a Query from a FindScooters request. Unset fields take
+// the defaults of the query parameters of the HTTP API.
+func queryFromPB(req *telemetrypb.FindScootersRequest) (Query, error) {
+	a := req.GetArea()
+	qry := Query{
+		Area:       Area{MinLat: a.GetMinLat(), MinLng: a.GetMinLng(), MaxLat: a.GetMaxLat(), MaxLng: a.GetMaxLng()},
+		Status:     Status(req.GetStatus()),
+		MinBattery: int(req.GetMinBattery()),
+		Limit:      int(req.GetLimit()),
+	}
+
+	if qry.Limit == 0 {
+		qry.Limit = DefaultPageSize
+	}
+
+	if req.GetCursor() != "" {
+		var err error
+		qry.After, err = parseCursor(req.GetCursor())
+		if err != nil {
+			return qry, err
+		}
+	}
+
+	return qry, nil
+}
+
+// eventFromPB builds an Event from its protobuf message. Invalid IDs are
+// reported on the field names of the message.
+func eventFromPB(pe *telemetrypb.Event) (Event, error) {
+	if pe == nil {
+		return Event{}, invalidField(ErrInvalidEvent, "event", "is required")
+	}
+
+	e := Event{
+		Type: EventType(pe.GetType()),
+		Lat:  pe.GetLat(),
+		Lng:  pe.GetLng(),
+	}
+
+	var err error
+	if pe.GetId() != "" {
+		e.ID, err = uuid.Parse(pe.GetId())
+		if err != nil {
+			return e, invalidField(ErrInvalidEvent, "event.id", "must be a UUID")
+		}
+	}
+
+	if pe.GetScooterId() != "" {
+		e.ScooterID, err = uuid.Parse(pe.GetScooterId())
+		if err != nil {
+			return e, invalidField(ErrInvalidEvent, "event.scooter_id", "must be a UUID")
+		}
+	}
+
+	if pe.OccurredAt != nil {
+		e.OccurredAt = pe.GetOccurredAt().AsTime()
+	}
+
+	if pe.Battery != nil {
+		battery := int(pe.GetBattery())
+		e.Battery = &battery
+	}
+
+	return e, nil
+}
+
+func pbScooter(s Scooter) *telemetrypb.Scooter {
+	return &telemetrypb.Scooter{
+		Id:          s.ID.String(),
+		Status:      string(s.Status),
+		Lat:         s.Lat,
+		Lng:         s.Lng,
+		Battery:     int32(s.Battery),
+		UpdatedAt:   pbTime(&s.UpdatedAt),
+		Version:     int32(s.Version),
+		LastEventAt: pbTime(s.LastEventAt),
+	}
+}
+
+func pbEventResult(res EventResult) *telemetrypb.EventResult {
+	return &telemetrypb.EventResult{
+		EventId:   res.EventID.String(),
+		ScooterId: res.ScooterID.String(),
+		Status:    string(res.Status),
+		Stale:     res.Stale,
+		Trip:      pbTrip(res.Trip),
+		Replayed:  res.Replayed,
+	}
+}
+
+func pbTrip(t *Trip) *telemetrypb.Trip {
+	if t == nil {
+		return nil
+	}
+
+	pt := &telemetrypb.Trip{
+		Id:        t.ID.String(),
+		ScooterId: t.ScooterID.String(),
+		ClientId:  t.ClientID,
+		StartedAt: pbTime(&t.StartedAt),
+		EndedAt:   pbTime(t.EndedAt),
+		Start:     pbPoint(&t.Start),
+		End:       pbPoint(t.End),
+		Distance:  t.Distance,
+	}
+
+	for _, p := range t.Path {
+		pt.Path = append(pt.Path, pbPoint(&p))
+	}
+
+	if f := t.Fare; f != nil {
+		pt.Fare = &telemetrypb.Fare{
+			Tariff:         f.Tariff,
+			Currency:       f.Currency,
+			UnlockFee:      f.UnlockFee,
+			Minutes:        int32(f.Minutes),
+			TimeCharge:     f.TimeCharge,
+			DistanceCharge: f.DistanceCharge,
+			Surcharge:      f.Surcharge,
+			Total:          f.Total,
+		}
+	}
+
+	return pt
+}
+
+func pbPoint(p *Point) *telemetrypb.Point {
+	if p == nil {
+		return nil
+	}
+
+	return &telemetrypb.Point{Lat: p.Lat, Lng: p.Lng}
+}
+
+// pbTime converts t, leaving nil and zero times unset.
+func pbTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
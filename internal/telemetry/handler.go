@@ -2,9 +2,12 @@ package telemetry
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"mime"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -23,94 +26,791 @@ func (h *Handler) WrapHandler(handler http.Handler) http.Handler {
 	return h.auth(handler)
 }
 
+// GetScooter returns a scooter with its version as ETag. A request whose
+// If-None-Match still holds the current version gets a 304 Not Modified.
 func (h *Handler) GetScooter(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.Err(w, r, http.StatusBadRequest, "invalid scooter id", err)
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
 		return
 	}
 
 	scooter, err := h.service.GetScooter(r.Context(), id)
 	if err != nil {
-		h.Err(w, r, http.StatusNotFound, err.Error(), err)
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", scooter.ETag())
+	if r.Header.Get("If-None-Match") == scooter.ETag() {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(scooter)
 	if err != nil {
-		h.Err(w, r, http.StatusInternalServerError, "response encoding error", err)
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) CreateScooter(w http.ResponseWriter, r *http.Request) {
+	var s Scooter
+	err := json.NewDecoder(r.Body).Decode(&s)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+		return
+	}
+
+	s, err = h.service.CreateScooter(r.Context(), s)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(s)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// StreamScooters streams the changes of the scooters inside the requested area
+// as Server-Sent Events, for live maps. Each change is a "scooter" event whose
+// ID can be sent back in Last-Event-ID to resume after a reconnection. A
+// "reset" event means changes were missed and the scooters must be reloaded.
+// Idle streams get a heartbeat comment; a client that falls behind is
+// disconnected and resumes when it reconnects.
+func (h *Handler) StreamScooters(w http.ResponseWriter, r *http.Request) {
+	qry, err := NewStreamQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.Err(w, r, errors.New("streaming unsupported by the response writer"))
+		return
+	}
+
+	sub, err := h.service.WatchScooters(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	if err == nil && sub.Reset() {
+		err = writeSSE(w, "", "reset", struct{}{})
+	}
+
+	heartbeat := time.NewTicker(StreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for err == nil {
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+
+		case c, ok := <-sub.Changes():
+			if !ok {
+				if sub.Lagged() {
+					log.Printf("stream: dropped slow client %s", r.RemoteAddr)
+				}
+				return
+			}
+
+			err = writeSSE(w, c.ID, "scooter", c.Scooter)
+		}
+	}
+}
+
+// ConnectDevice upgrades the request to the WebSocket channel of a scooter
+// device. The device sends {"type":"event","ref":...,"event":{...}} messages
+// and gets an "ack" with the event result or an "error" for each, in order.
+// The server pushes "command" messages when the scooter is changed by someone
+// else, starting with the status it is in when the device connects.
+func (h *Handler) ConnectDevice(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	scooter, err := h.service.GetScooter(r.Context(), id)
+	if err != nil {
+		h.Err(w, r, err)
 		return
 	}
+
+	sub, err := h.service.WatchScooters(r.Context(), StreamQuery{ScooterID: id})
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+	defer sub.Close()
+
+	conn, err := deviceUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already replied.
+		log.Printf("device %s: upgrade error: %v", id, err)
+		return
+	}
+
+	origin, _ := RequestID(r.Context())
+	c := &deviceChannel{
+		service:   h.service,
+		conn:      conn,
+		scooterID: id,
+		origin:    origin,
+		status:    scooter.Status,
+		sub:       sub,
+		out:       make(chan deviceReply, deviceOutbox),
+	}
+
+	c.serve(r.Context())
 }
 
+// UpdateScooter partially updates a scooter. Sending the ETag of the scooter
+// in If-Match makes the update fail with 412 if it has changed in between.
 func (h *Handler) UpdateScooter(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		h.Err(w, r, http.StatusBadRequest, "invalid scooter id", err)
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
 		return
 	}
 
-	var s Scooter
-	err = json.NewDecoder(r.Body).Decode(&s)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	var p ScooterPatch
+	err = json.NewDecoder(r.Body).Decode(&p)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+		return
+	}
+
+	p.ScooterID = id
+	p.Version = version
+
+	scooter, err := h.service.UpdateScooter(r.Context(), p)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", scooter.ETag())
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(scooter)
 	if err != nil {
-		h.Err(w, r, http.StatusBadRequest, "unmarshalable request body", err)
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
 		return
 	}
+}
 
-	s.ID = id
+func (h *Handler) DeleteScooter(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
 
-	err = h.service.UpdateScooter(r.Context(), s)
+	err = h.service.DeleteScooter(r.Context(), id)
 	if err != nil {
-		h.Err(w, r, http.StatusInternalServerError, err.Error(), err)
+		h.Err(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) FindScooters(w http.ResponseWriter, r *http.Request) {
-	qry, err := NewQuery(r)
+func (h *Handler) FindNearbyScooters(w http.ResponseWriter, r *http.Request) {
+	qry, err := NewNearbyQuery(r)
 	if err != nil {
-		h.Err(w, r, http.StatusBadRequest, "invalid area parameters", err)
+		h.Err(w, r, err)
 		return
 	}
 
-	result, err := h.service.FindScooters(r.Context(), qry)
+	result, err := h.service.FindNearbyScooters(r.Context(), qry)
 	if err != nil {
-		h.Err(w, r, http.StatusInternalServerError, err.Error(), err)
+		h.Err(w, r, err)
 		return
 	}
 
+	if result == nil {
+		result = []NearbyScooter{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(result)
 	if err != nil {
-		h.Err(w, r, http.StatusInternalServerError, "response encoding error", err)
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// ScooterGrid returns the scooter counts by grid cell and status over an
+// area, for heatmaps at zoom levels where single scooters are not useful.
+func (h *Handler) ScooterGrid(w http.ResponseWriter, r *http.Request) {
+	qry, err := NewGridQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	grid, err := h.service.ScooterGrid(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(grid)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
 		return
 	}
 }
 
+// ImportScooters upserts scooters from a CSV (text/csv) or GeoJSON
+// (application/geo+json) request body and reports row-level errors.
+func (h *Handler) ImportScooters(w http.ResponseWriter, r *http.Request) {
+	format, err := fleetFormatOf(r.Header.Get("Content-Type"))
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	res, err := h.service.ImportScooters(r.Context(), format, r.Body)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// ExportScooters streams the whole fleet as CSV (default) or GeoJSON
+// (format=geojson).
+func (h *Handler) ExportScooters(w http.ResponseWriter, r *http.Request) {
+	if !IsOperator(r.Context()) {
+		h.Err(w, r, ErrOperatorOnly)
+		return
+	}
+
+	format := FleetFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatCSV
+	}
+
+	mediaType, ok := fleetMediaTypes[format]
+	if !ok {
+		h.Err(w, r, invalidField(ErrInvalidQuery, "format", fmt.Sprintf("unsupported format %q", format)))
+		return
+	}
+
+	aw := &attachmentWriter{ResponseWriter: w, mediaType: mediaType, filename: "fleet." + string(format)}
+	err := h.service.ExportScooters(r.Context(), format, aw)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+}
+
+// attachmentWriter sets the headers of a download on its first write, so that
+// an export failing before any output gets a plain error response.
+type attachmentWriter struct {
+	http.ResponseWriter
+	mediaType string
+	filename  string
+	started   bool
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.Header().Set("Content-Type", w.mediaType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, w.filename))
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (h *Handler) FindScooters(w http.ResponseWriter, r *http.Request) {
+	qry, err := NewQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	page, err := h.service.FindScooters(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	var body interface{} = page
+	mediaType := negotiate(r, mediaTypeJSON, mediaTypeGeoJSON)
+	if mediaType == mediaTypeGeoJSON {
+		fc := newScooterCollection(page.Scooters)
+		fc.Next = page.Next
+		body = fc
+	}
+
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Type", mediaType)
+	err = json.NewEncoder(w).Encode(body)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// ReportEvent accepts a single event. A request retried with the same
+// Idempotency-Key gets the original response, flagged with the
+// Idempotent-Replayed header.
 func (h *Handler) ReportEvent(w http.ResponseWriter, r *http.Request) {
 	var event Event
 	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
-		h.Err(w, r, http.StatusBadRequest, "invalid event payload", err)
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidEvent, err))
+		return
+	}
+
+	key, err := idempotencyKeyHeader(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	if key != "" {
+		ctx = WithIdempotencyKey(ctx, key)
+	}
+
+	res, err := h.service.ReportEvent(ctx, event)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	if res.Replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// ReportEvents ingests a batch of events buffered by a device, sent as a JSON
+// array or as NDJSON. Every event gets its own result; a rejected event does
+// not fail the batch.
+func (h *Handler) ReportEvents(w http.ResponseWriter, r *http.Request) {
+	batch, err := readEventBatch(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	events := make([]Event, 0, len(batch))
+	for _, b := range batch {
+		if b.err == nil {
+			events = append(events, b.event)
+		}
+	}
+
+	items, err := h.service.ReportEvents(r.Context(), events)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	results := make([]batchResult, 0, len(batch))
+	for i, b := range batch {
+		item := BatchItem{Err: b.err}
+		if b.err == nil {
+			item, items = items[0], items[1:]
+		}
+		results = append(results, newBatchResult(i, item))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(results)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	var change StatusChange
+	err = json.NewDecoder(r.Body).Decode(&change)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+		return
+	}
+
+	change.ScooterID = id
+
+	scooter, err := h.service.ChangeStatus(r.Context(), change)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(scooter)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) ReserveScooter(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	res, err := h.service.ReserveScooter(r.Context(), id)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) GetTrip(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidTripID, "id", "must be a UUID"))
+		return
+	}
+
+	trip, err := h.service.GetTrip(r.Context(), id)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(trip)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) FindTrips(w http.ResponseWriter, r *http.Request) {
+	qry, err := NewTripQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	trips, err := h.service.FindTrips(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	if trips == nil {
+		trips = []Trip{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(trips)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) CreateZone(w http.ResponseWriter, r *http.Request) {
+	// A zone without the active flag is enforced.
+	z := Zone{Active: true}
+	err := json.NewDecoder(r.Body).Decode(&z)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidBody, err))
 		return
 	}
 
-	err := h.service.ReportEvent(r.Context(), event)
+	z, err = h.service.CreateZone(r.Context(), z)
 	if err != nil {
-		h.Err(w, r, http.StatusInternalServerError, err.Error(), err)
+		h.Err(w, r, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(z)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidZoneID, "id", "must be a UUID"))
+		return
+	}
+
+	z := Zone{Active: true}
+	err = json.NewDecoder(r.Body).Decode(&z)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("%w: %v", ErrInvalidBody, err))
+		return
+	}
+
+	z.ID = id
+
+	z, err = h.service.UpdateZone(r.Context(), z)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(z)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
 }
 
-func (h *Handler) Err(w http.ResponseWriter, r *http.Request, status int, msg string, err error) {
-	http.Error(w, msg, status)
+func (h *Handler) GetZone(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidZoneID, "id", "must be a UUID"))
+		return
+	}
+
+	z, err := h.service.GetZone(r.Context(), id)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(z)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidZoneID, "id", "must be a UUID"))
+		return
+	}
+
+	err = h.service.DeleteZone(r.Context(), id)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.service.ListZones(r.Context())
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	if zones == nil {
+		zones = []Zone{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(zones)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// FindTripViolations lists the zone violations recorded during a trip.
+func (h *Handler) FindTripViolations(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidTripID, "id", "must be a UUID"))
+		return
+	}
+
+	h.findViolations(w, r, ViolationQuery{TripID: id})
+}
+
+// FindScooterViolations lists the zone violations recorded for a scooter.
+func (h *Handler) FindScooterViolations(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	h.findViolations(w, r, ViolationQuery{ScooterID: id})
+}
+
+// FindScooterEvents lists the events recorded for a scooter, oldest first,
+// stale ones included, so that support can retrace a ride and its fare.
+func (h *Handler) FindScooterEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	qry, err := NewEventQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	qry.ScooterID = id
+
+	events, err := h.service.FindEvents(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	if events == nil {
+		events = []Event{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(events)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// ScooterTrack exports the path of a scooter as a GeoJSON LineString
+// (default), GPX (format=gpx) or encoded polyline (format=polyline).
+func (h *Handler) ScooterTrack(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		h.Err(w, r, invalidField(ErrInvalidID, "id", "must be a UUID"))
+		return
+	}
+
+	format := TrackFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = TrackGeoJSON
+	}
+
+	mediaType, ok := trackMediaTypes[format]
+	if !ok {
+		h.Err(w, r, invalidField(ErrInvalidQuery, "format", fmt.Sprintf("unsupported format %q", format)))
+		return
+	}
+
+	qry, err := NewTrackQuery(r)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	qry.ScooterID = id
+
+	track, err := h.service.Track(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	if track.Truncated {
+		w.Header().Set("X-Track-Truncated", "true")
+	}
+	if format == TrackGPX {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.gpx"`, id))
+	}
+
+	err = WriteTrack(format, w, track)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+func (h *Handler) findViolations(w http.ResponseWriter, r *http.Request, qry ViolationQuery) {
+	violations, err := h.service.FindViolations(r.Context(), qry)
+	if err != nil {
+		h.Err(w, r, err)
+		return
+	}
+
+	if violations == nil {
+		violations = []Violation{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(violations)
+	if err != nil {
+		h.Err(w, r, fmt.Errorf("response encoding error: %w", err))
+		return
+	}
+}
+
+// Err writes err as a problem+json response and logs it. Only domain errors
+// are described to the client; anything else is reported as an internal
+// error and its details are kept in the logs.
+func (h *Handler) Err(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, r, err)
+
 	clientID, _ := ClientID(r.Context())
 	prefix := "handler error:"
 
@@ -118,10 +818,37 @@ func (h *Handler) Err(w http.ResponseWriter, r *http.Request, status int, msg st
 		prefix = fmt.Sprintf("[%s] handler error:", clientID)
 	}
 
+	if requestID, ok := RequestID(r.Context()); ok {
+		prefix = fmt.Sprintf("%s request %s:", prefix, requestID)
+	}
+
+	log.Printf("%s %v", prefix, err)
+}
+
+var fleetMediaTypes = map[FleetFormat]string{
+	FormatCSV:     "text/csv",
+	FormatGeoJSON: mediaTypeGeoJSON,
+}
+
+var trackMediaTypes = map[TrackFormat]string{
+	TrackGeoJSON:  mediaTypeGeoJSON,
+	TrackGPX:      mediaTypeGPX,
+	TrackPolyline: mediaTypeJSON,
+}
+
+// fleetFormatOf returns the fleet format matching a request content type.
+func fleetFormatOf(contentType string) (FleetFormat, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		log.Printf("%s %s: %v", prefix, msg, err)
-		return
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, contentType)
 	}
 
-	log.Printf("%s %s", prefix, msg)
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/geo+json", "application/json":
+		return FormatGeoJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, mediaType)
+	}
 }
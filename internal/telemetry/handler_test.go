@@ -3,10 +3,15 @@ package telemetry_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,11 +22,12 @@ import (
 func TestGetScooterHandler(t *testing.T) {
 	id := uuid.New()
 	tests := []struct {
-		name       string
-		id         string
-		svc        *mockService
-		wantStatus int
-		wantBody   string
+		name        string
+		id          string
+		ifNoneMatch string
+		svc         *mockService
+		wantStatus  int
+		wantBody    string
 	}{
 		{
 			name: "happy path",
@@ -30,7 +36,7 @@ func TestGetScooterHandler(t *testing.T) {
 				GetScooterFunc: happyGetScooter(id),
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `{"id":"` + id.String() + `","status":"free","lat":0,"lng":0,"updatedAt":"`,
+			wantBody:   `{"id":"` + id.String() + `","status":"free","lat":0,"lng":0,"battery":0,"updatedAt":"`,
 		},
 		{
 			name: "not found",
@@ -41,6 +47,15 @@ func TestGetScooterHandler(t *testing.T) {
 			wantStatus: http.StatusNotFound,
 			wantBody:   "not found",
 		},
+		{
+			name: "service error",
+			id:   id.String(),
+			svc: &mockService{
+				GetScooterFunc: failGetScooter,
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal","detail":"internal error"`,
+		},
 		{
 			name: "invalid id",
 			id:   "not-a-uuid",
@@ -50,6 +65,15 @@ func TestGetScooterHandler(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantBody:   "invalid scooter id",
 		},
+		{
+			name:        "not modified",
+			id:          id.String(),
+			ifNoneMatch: `"3"`,
+			svc: &mockService{
+				GetScooterFunc: happyGetScooter(id),
+			},
+			wantStatus: http.StatusNotModified,
+		},
 	}
 
 	for _, tt := range tests {
@@ -57,6 +81,9 @@ func TestGetScooterHandler(t *testing.T) {
 			h := telemetry.NewHandler(tt.svc)
 			r := httptest.NewRequest(http.MethodGet, "/scooters/"+tt.id, nil)
 			r.SetPathValue("id", tt.id)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
 			w := httptest.NewRecorder()
 			h.GetScooter(w, r)
 
@@ -67,8 +94,11 @@ func TestGetScooterHandler(t *testing.T) {
 				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
 			}
 
-			if tt.wantStatus == http.StatusOK {
-				if len(body) == 0 || body[:len(tt.wantBody)] != tt.wantBody {
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusNotModified {
+				if got := resp.Header.Get("ETag"); got != `"3"` {
+					t.Errorf("expected ETag %q, got %q", `"3"`, got)
+				}
+				if !strings.HasPrefix(body, tt.wantBody) {
 					t.Errorf("expected body to start with %q, got %q", tt.wantBody, body)
 				}
 			} else {
@@ -80,30 +110,156 @@ func TestGetScooterHandler(t *testing.T) {
 	}
 }
 
+func TestErrorProblem(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantProblem telemetry.Problem
+	}{
+		{
+			name:       "storage error is not leaked",
+			err:        errors.New(`pq: relation "scooters" does not exist`),
+			wantStatus: http.StatusInternalServerError,
+			wantProblem: telemetry.Problem{
+				Type:      "about:blank",
+				Title:     "Internal Server Error",
+				Status:    http.StatusInternalServerError,
+				Code:      "internal",
+				Detail:    "internal error",
+				Instance:  "/api/v1/scooters/" + id.String(),
+				RequestID: "req-42",
+			},
+		},
+		{
+			name:       "field error",
+			err:        telemetry.DefaultValidator(telemetry.OpFindNearby, telemetry.NearbyQuery{Center: telemetry.Point{Lat: 95}}),
+			wantStatus: http.StatusBadRequest,
+			wantProblem: telemetry.Problem{
+				Type:      "about:blank",
+				Title:     "Bad Request",
+				Status:    http.StatusBadRequest,
+				Code:      "invalid_query",
+				Detail:    "invalid query: invalid coordinates",
+				Instance:  "/api/v1/scooters/" + id.String(),
+				RequestID: "req-42",
+				Errors:    []telemetry.FieldError{{Field: "lat", Message: "invalid coordinates"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(&mockService{
+				GetScooterFunc: func(context.Context, uuid.UUID) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, tt.err
+				},
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/scooters/"+id.String(), nil)
+			r.SetPathValue("id", id.String())
+			r.Header.Set("X-Request-ID", "req-42")
+			w := httptest.NewRecorder()
+
+			telemetry.RequestIDMiddleware(http.HandlerFunc(h.GetScooter)).ServeHTTP(w, r)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("expected problem content type, got %q", ct)
+			}
+
+			if got := resp.Header.Get("X-Request-ID"); got != "req-42" {
+				t.Errorf("expected request ID to be echoed, got %q", got)
+			}
+
+			var got telemetry.Problem
+			err := json.NewDecoder(resp.Body).Decode(&got)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantProblem) {
+				t.Errorf("problem = %+v, want %+v", got, tt.wantProblem)
+			}
+		})
+	}
+}
+
 func TestUpdateScooterHandler(t *testing.T) {
 	id := uuid.New()
+	battery := 80
 	tests := []struct {
-		name       string
-		id         string
-		body       telemetry.Scooter
-		svc        *mockService
-		wantStatus int
-		wantBody   string
+		name        string
+		id          string
+		ifMatch     string
+		body        string
+		svc         *mockService
+		wantStatus  int
+		wantBody    string
+		wantVersion int
 	}{
 		{
 			name: "happy path",
 			id:   id.String(),
-			body: telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusOccupied},
+			body: `{"battery":80}`,
+			svc: &mockService{
+				UpdateScooterFunc: happyUpdateScooter(telemetry.ScooterPatch{ScooterID: id, Battery: &battery}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"version":4`,
+		},
+		{
+			name:    "if-match",
+			id:      id.String(),
+			ifMatch: `"3"`,
+			body:    `{"battery":80}`,
+			svc: &mockService{
+				UpdateScooterFunc: happyUpdateScooter(telemetry.ScooterPatch{ScooterID: id, Version: 3, Battery: &battery}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"version":4`,
+		},
+		{
+			name:    "if-match any",
+			id:      id.String(),
+			ifMatch: "*",
+			body:    `{"battery":80}`,
+			svc: &mockService{
+				UpdateScooterFunc: happyUpdateScooter(telemetry.ScooterPatch{ScooterID: id, Battery: &battery}),
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "stale version",
+			id:      id.String(),
+			ifMatch: `"2"`,
+			body:    `{"battery":80}`,
+			svc: &mockService{
+				UpdateScooterFunc: conflictUpdateScooter,
+			},
+			wantStatus: http.StatusPreconditionFailed,
+			wantBody:   "version conflict",
+		},
+		{
+			name:    "unknown entity tag",
+			id:      id.String(),
+			ifMatch: `W/"abc"`,
+			body:    `{"battery":80}`,
 			svc: &mockService{
-				UpdateScooterFunc: happyUpdateScooter(id),
+				UpdateScooterFunc: alwaysNilUpdateScooter,
 			},
-			wantStatus: http.StatusNoContent,
-			wantBody:   "",
+			wantStatus: http.StatusPreconditionFailed,
+			wantBody:   "version conflict",
 		},
 		{
 			name: "invalid id",
 			id:   "not-a-uuid",
-			body: telemetry.Scooter{},
+			body: `{}`,
 			svc: &mockService{
 				UpdateScooterFunc: alwaysNilUpdateScooter,
 			},
@@ -113,41 +269,43 @@ func TestUpdateScooterHandler(t *testing.T) {
 		{
 			name: "unmarshal error",
 			id:   id.String(),
-			body: telemetry.Scooter{}, // will send invalid JSON
+			body: "not-json",
 			svc: &mockService{
 				UpdateScooterFunc: alwaysNilUpdateScooter,
 			},
 			wantStatus: http.StatusBadRequest,
 			wantBody:   "unmarshalable request body",
 		},
+		{
+			name: "not found",
+			id:   id.String(),
+			body: `{"battery":80}`,
+			svc: &mockService{
+				UpdateScooterFunc: notFoundUpdateScooter,
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "not found",
+		},
 		{
 			name: "service error",
 			id:   id.String(),
-			body: telemetry.Scooter{ID: id, Status: telemetry.StatusOccupied},
+			body: `{"battery":80}`,
 			svc: &mockService{
 				UpdateScooterFunc: failUpdateScooter,
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   "fail",
+			wantBody:   `"code":"internal","detail":"internal error"`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := telemetry.NewHandler(tt.svc)
-			var reqBody []byte
-			if tt.name == "unmarshal error" {
-				reqBody = []byte("not-json")
-			} else {
-				var err error
-				reqBody, err = json.Marshal(tt.body)
-				if err != nil {
-					t.Fatalf("marshal error: %v", err)
-				}
-			}
-
-			r := httptest.NewRequest(http.MethodPut, "/scooters/"+tt.id, bytes.NewReader(reqBody))
+			r := httptest.NewRequest(http.MethodPatch, "/scooters/"+tt.id, bytes.NewReader([]byte(tt.body)))
 			r.SetPathValue("id", tt.id)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
 
 			w := httptest.NewRecorder()
 			h.UpdateScooter(w, r)
@@ -159,6 +317,10 @@ func TestUpdateScooterHandler(t *testing.T) {
 				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
 			}
 
+			if tt.wantStatus == http.StatusOK && resp.Header.Get("ETag") != `"4"` {
+				t.Errorf("expected ETag %q, got %q", `"4"`, resp.Header.Get("ETag"))
+			}
+
 			if tt.wantBody != "" && !bytes.Contains([]byte(body), []byte(tt.wantBody)) {
 				t.Errorf("expected body to contain %q, got %q", tt.wantBody, body)
 			}
@@ -176,7 +338,13 @@ func TestFindScootersHandler(t *testing.T) {
 			MaxLng: 18,
 		},
 		Status: telemetry.StatusFree,
+		Limit:  telemetry.DefaultPageSize,
 	}
+	paged := qry
+	paged.Limit = 2
+	paged.After = id
+	cursor := base64.RawURLEncoding.EncodeToString(id[:])
+
 	tests := []struct {
 		name       string
 		params     string
@@ -188,10 +356,28 @@ func TestFindScootersHandler(t *testing.T) {
 			name:   "happy path",
 			params: "?minLat=51&minLng=17&maxLat=52&maxLng=18&status=free",
 			svc: &mockService{
-				FindScootersFunc: happyFindScooters(qry, []telemetry.Scooter{{ID: id, Status: telemetry.StatusFree}}),
+				FindScootersFunc: happyFindScooters(qry, telemetry.ScooterPage{Scooters: []telemetry.Scooter{{ID: id, Status: telemetry.StatusFree}}}),
 			},
 			wantStatus: http.StatusOK,
-			wantBody:   `[{"id":"` + id.String() + `","status":"free","lat":0,"lng":0,"updatedAt":"`,
+			wantBody:   `{"scooters":[{"id":"` + id.String() + `","status":"free","lat":0,"lng":0,"battery":0,"updatedAt":"`,
+		},
+		{
+			name:   "next page",
+			params: "?minLat=51&minLng=17&maxLat=52&maxLng=18&status=free&limit=2&cursor=" + cursor,
+			svc: &mockService{
+				FindScootersFunc: happyFindScooters(paged, telemetry.ScooterPage{Scooters: []telemetry.Scooter{}, Next: "abc"}),
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"scooters":[],"next":"abc"}`,
+		},
+		{
+			name:   "invalid cursor",
+			params: "?minLat=51&minLng=17&maxLat=52&maxLng=18&cursor=@@",
+			svc: &mockService{
+				FindScootersFunc: alwaysNilFindScooters,
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid cursor",
 		},
 		{
 			name:   "invalid area param",
@@ -200,7 +386,16 @@ func TestFindScootersHandler(t *testing.T) {
 				FindScootersFunc: alwaysNilFindScooters,
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   "invalid area parameters",
+			wantBody:   `"errors":[{"field":"minLat","message":"must be a number"}]`,
+		},
+		{
+			name:   "infinite area param",
+			params: "?minLat=51&minLng=17&maxLat=Inf&maxLng=18",
+			svc: &mockService{
+				FindScootersFunc: alwaysNilFindScooters,
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"maxLat","message":"must be a number"}]`,
 		},
 		{
 			name:   "service error",
@@ -209,7 +404,7 @@ func TestFindScootersHandler(t *testing.T) {
 				FindScootersFunc: failFindScooters,
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   "fail",
+			wantBody:   `"code":"internal","detail":"internal error"`,
 		},
 	}
 
@@ -243,12 +438,12 @@ func TestFindScootersHandler(t *testing.T) {
 
 func TestReportEventHandler(t *testing.T) {
 	validEvent := telemetry.Event{
-		ID:        uuid.New(),
-		ScooterID: uuid.New(),
-		Type:      telemetry.EventTripStart,
-		Timestamp: time.Now(),
-		Lat:       51.1,
-		Lng:       17.0,
+		ID:         uuid.New(),
+		ScooterID:  uuid.New(),
+		Type:       telemetry.EventTripStart,
+		OccurredAt: time.Now(),
+		Lat:        51.1,
+		Lng:        17.0,
 	}
 
 	tests := []struct {
@@ -262,38 +457,80 @@ func TestReportEventHandler(t *testing.T) {
 			name: "happy path",
 			body: validEvent,
 			mockSvc: &mockService{
-				ReportEventFunc: func(ctx context.Context, e telemetry.Event) error {
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
 					if e.ID != validEvent.ID ||
 						e.ScooterID != validEvent.ScooterID ||
 						e.Type != validEvent.Type ||
 						e.Lat != validEvent.Lat ||
 						e.Lng != validEvent.Lng {
-						return errors.New("event mismatch")
+						return telemetry.EventResult{}, errors.New("event mismatch")
 					}
-					// Do NOT compare e.Timestamp
-					return nil
+					// Do NOT compare e.OccurredAt
+					return telemetry.EventResult{EventID: e.ID, ScooterID: e.ScooterID, Status: telemetry.StatusOccupied}, nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"status":"occupied"`,
+		},
+		{
+			name: "trip end with fare",
+			body: validEvent,
+			mockSvc: &mockService{
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					return telemetry.EventResult{
+						EventID:   e.ID,
+						ScooterID: e.ScooterID,
+						Status:    telemetry.StatusFree,
+						Trip:      &telemetry.Trip{Fare: &telemetry.Fare{Currency: "CAD", Total: 415}},
+					}, nil
 				},
 			},
 			wantStatus: http.StatusCreated,
-			wantBody:   "",
+			wantBody:   `"total":415`,
 		},
 		{
 			name: "invalid payload",
 			body: "not-json",
 			mockSvc: &mockService{
-				ReportEventFunc: func(ctx context.Context, e telemetry.Event) error { return nil },
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					return telemetry.EventResult{}, nil
+				},
 			},
 			wantStatus: http.StatusBadRequest,
-			wantBody:   "invalid event payload",
+			wantBody:   `"code":"invalid_event"`,
+		},
+		{
+			name: "invalid transition",
+			body: validEvent,
+			mockSvc: &mockService{
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					return telemetry.EventResult{}, fmt.Errorf("%w: trip_start on occupied scooter", telemetry.ErrInvalidTransition)
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "invalid status transition",
+		},
+		{
+			name: "not ride owner",
+			body: validEvent,
+			mockSvc: &mockService{
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					return telemetry.EventResult{}, telemetry.ErrNotRideOwner
+				},
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "ride belongs to another client",
 		},
 		{
 			name: "service error",
 			body: validEvent,
 			mockSvc: &mockService{
-				ReportEventFunc: func(ctx context.Context, e telemetry.Event) error { return errors.New("fail") },
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					return telemetry.EventResult{}, errors.New("fail")
+				},
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantBody:   "fail",
+			wantBody:   `"code":"internal","detail":"internal error"`,
 		},
 	}
 
@@ -329,79 +566,1323 @@ func TestReportEventHandler(t *testing.T) {
 	}
 }
 
-type mockService struct {
-	GetScooterFunc    func(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error)
-	UpdateScooterFunc func(ctx context.Context, s telemetry.Scooter) error
-	FindScootersFunc  func(ctx context.Context, qry telemetry.Query) ([]telemetry.Scooter, error)
-	ReportEventFunc   func(ctx context.Context, e telemetry.Event) error
-}
+func TestReportEventIdempotencyKey(t *testing.T) {
+	event := telemetry.Event{ScooterID: uuid.New(), Type: telemetry.EventLocation, Lat: 45.0, Lng: -75.0}
+	body, _ := json.Marshal(event)
 
-func (m *mockService) GetScooter(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error) {
-	return m.GetScooterFunc(ctx, id)
-}
+	tests := []struct {
+		name         string
+		key          string
+		replayed     bool
+		wantStatus   int
+		wantReplayed string
+		wantBody     string
+	}{
+		{name: "no key", wantStatus: http.StatusCreated},
+		{name: "first attempt", key: "evt-1", wantStatus: http.StatusCreated},
+		{name: "replayed", key: "evt-1", replayed: true, wantStatus: http.StatusCreated, wantReplayed: "true"},
+		{
+			name:       "key too long",
+			key:        strings.Repeat("k", 256),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"field":"Idempotency-Key"`,
+		},
+	}
 
-func (m *mockService) UpdateScooter(ctx context.Context, s telemetry.Scooter) error {
-	return m.UpdateScooterFunc(ctx, s)
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				ReportEventFunc: func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+					key, _ := telemetry.IdempotencyKey(ctx)
+					if key != tt.key {
+						return telemetry.EventResult{}, fmt.Errorf("idempotency key = %q, want %q", key, tt.key)
+					}
+					return telemetry.EventResult{ScooterID: e.ScooterID, Status: telemetry.StatusOccupied, Replayed: tt.replayed}, nil
+				},
+			}
+			h := telemetry.NewHandler(svc)
 
-func (m *mockService) FindScooters(ctx context.Context, qry telemetry.Query) ([]telemetry.Scooter, error) {
-	return m.FindScootersFunc(ctx, qry)
-}
+			r := httptest.NewRequest(http.MethodPost, "/events", bytes.NewReader(body))
+			if tt.key != "" {
+				r.Header.Set("Idempotency-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
 
-func (m *mockService) ReportEvent(ctx context.Context, e telemetry.Event) error {
-	if m.ReportEventFunc != nil {
-		return m.ReportEventFunc(ctx, e)
+			h.ReportEvent(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Idempotent-Replayed"); got != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %q, want %q", got, tt.wantReplayed)
+			}
+
+			if strings.Contains(w.Body.String(), "replayed") {
+				t.Errorf("expected the replayed flag to stay out of the body, got %s", w.Body.String())
+			}
+
+			if tt.wantBody != "" && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
 	}
-	return nil
 }
 
-func happyGetScooter(expectedID uuid.UUID) func(context.Context, uuid.UUID) (telemetry.Scooter, error) {
-	return func(ctx context.Context, gotID uuid.UUID) (telemetry.Scooter, error) {
-		if gotID != expectedID {
-			return telemetry.Scooter{}, errors.New("wrong id")
-		}
-		return telemetry.Scooter{ID: expectedID, Status: telemetry.StatusFree}, nil
+func TestReportEventsHandler(t *testing.T) {
+	id := uuid.New().String()
+	location := `{"scooterId":"` + id + `","type":"location","lat":51.1,"lng":17.0}`
+	tripStart := `{"scooterId":"` + id + `","type":"trip_start"}`
+
+	// The mock rejects trip_start events and accepts anything else.
+	svc := &mockService{
+		ReportEventsFunc: func(ctx context.Context, events []telemetry.Event) ([]telemetry.BatchItem, error) {
+			items := make([]telemetry.BatchItem, len(events))
+			for i, e := range events {
+				if e.Type == telemetry.EventTripStart {
+					items[i].Err = fmt.Errorf("%w: trip_start on occupied scooter", telemetry.ErrInvalidTransition)
+					continue
+				}
+				items[i].Result = telemetry.EventResult{ScooterID: e.ScooterID, Status: telemetry.StatusOccupied}
+			}
+			return items, nil
+		},
 	}
-}
 
-func notFoundGetScooter(context.Context, uuid.UUID) (telemetry.Scooter, error) {
-	return telemetry.Scooter{}, errors.New("not found")
-}
+	tests := []struct {
+		name         string
+		contentType  string
+		body         string
+		wantStatus   int
+		wantStatuses []int
+		wantBody     string
+	}{
+		{
+			name:         "json array",
+			contentType:  "application/json",
+			body:         `[` + location + `,` + tripStart + `,` + location + `]`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusCreated, http.StatusConflict, http.StatusCreated},
+		},
+		{
+			name:         "ndjson",
+			contentType:  "application/x-ndjson",
+			body:         location + "\n\n" + tripStart + "\n" + location + "\n",
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusCreated, http.StatusConflict, http.StatusCreated},
+		},
+		{
+			name:         "undecodable item",
+			contentType:  "application/x-ndjson",
+			body:         location + "\n{\"type\":42}\n" + location,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated},
+		},
+		{
+			name:        "not an array",
+			contentType: "application/json",
+			body:        location,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    "expected an array of events",
+		},
+		{
+			name:        "empty batch",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    "empty batch",
+		},
+		{
+			name:        "too many events",
+			contentType: "application/x-ndjson",
+			body:        strings.Repeat(location+"\n", telemetry.MaxBatchSize+1),
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    "more than",
+		},
+	}
 
-func alwaysNilGetScooter(context.Context, uuid.UUID) (telemetry.Scooter, error) {
-	return telemetry.Scooter{}, nil
-}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodPost, "/events:batch", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
 
-func happyUpdateScooter(expectedID uuid.UUID) func(context.Context, telemetry.Scooter) error {
-	return func(ctx context.Context, s telemetry.Scooter) error {
-		if s.ID != expectedID {
-			return errors.New("id not overwritten")
-		}
-		return nil
+			h.ReportEvents(w, r)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, resp.StatusCode, w.Body.String())
+			}
+
+			if tt.wantBody != "" && !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+
+			if tt.wantStatuses == nil {
+				return
+			}
+
+			var results []struct {
+				Index  int    `json:"index"`
+				Status int    `json:"status"`
+				Error  string `json:"error"`
+			}
+			err := json.NewDecoder(resp.Body).Decode(&results)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+
+			if len(results) != len(tt.wantStatuses) {
+				t.Fatalf("expected %d results, got %d", len(tt.wantStatuses), len(results))
+			}
+
+			for i, res := range results {
+				if res.Index != i || res.Status != tt.wantStatuses[i] {
+					t.Errorf("result %d: expected index %d status %d, got %+v", i, i, tt.wantStatuses[i], res)
+				}
+				if (res.Status >= 400) != (res.Error != "") {
+					t.Errorf("result %d: unexpected error %q for status %d", i, res.Error, res.Status)
+				}
+			}
+		})
 	}
 }
 
-func alwaysNilUpdateScooter(context.Context, telemetry.Scooter) error {
-	return nil
+func TestChangeStatusHandler(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "happy path",
+			id:   id.String(),
+			body: `{"status":"maintenance"}`,
+			svc: &mockService{
+				ChangeStatusFunc: func(ctx context.Context, change telemetry.StatusChange) (telemetry.Scooter, error) {
+					if change.ScooterID != id || change.Status != telemetry.StatusMaintenance {
+						return telemetry.Scooter{}, errors.New("wrong change")
+					}
+					return telemetry.Scooter{ID: id, Status: change.Status}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"maintenance"`,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			body:       `{"status":"maintenance"}`,
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid scooter id",
+		},
+		{
+			name: "not an operator",
+			id:   id.String(),
+			body: `{"status":"maintenance"}`,
+			svc: &mockService{
+				ChangeStatusFunc: func(context.Context, telemetry.StatusChange) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, telemetry.ErrOperatorOnly
+				},
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "operator credentials required",
+		},
+		{
+			name: "invalid status",
+			id:   id.String(),
+			body: `{"status":"broken"}`,
+			svc: &mockService{
+				ChangeStatusFunc: func(context.Context, telemetry.StatusChange) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, telemetry.ErrInvalidStatus
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid status",
+		},
+		{
+			name: "illegal transition",
+			id:   id.String(),
+			body: `{"status":"free"}`,
+			svc: &mockService{
+				ChangeStatusFunc: func(context.Context, telemetry.StatusChange) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, telemetry.ErrInvalidTransition
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "invalid status transition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodPut, "/scooters/"+tt.id+"/status", bytes.NewReader([]byte(tt.body)))
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.ChangeStatus(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReserveScooterHandler(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name       string
+		id         string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "happy path",
+			id:   id.String(),
+			svc: &mockService{
+				ReserveScooterFunc: func(ctx context.Context, gotID uuid.UUID) (telemetry.Reservation, error) {
+					return telemetry.Reservation{ScooterID: gotID, ClientID: "rider-1"}, nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"scooterId":"` + id.String() + `"`,
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid scooter id",
+		},
+		{
+			name: "scooter not free",
+			id:   id.String(),
+			svc: &mockService{
+				ReserveScooterFunc: func(context.Context, uuid.UUID) (telemetry.Reservation, error) {
+					return telemetry.Reservation{}, telemetry.ErrInvalidTransition
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "invalid status transition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodPost, "/scooters/"+tt.id+"/reservations", nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.ReserveScooter(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetTripHandler(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name       string
+		id         string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "happy path",
+			id:   id.String(),
+			svc: &mockService{
+				GetTripFunc: func(ctx context.Context, gotID uuid.UUID) (telemetry.Trip, error) {
+					return telemetry.Trip{ID: gotID, ClientID: "rider-1"}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"clientId":"rider-1"`,
+		},
+		{
+			name: "not found",
+			id:   id.String(),
+			svc: &mockService{
+				GetTripFunc: func(context.Context, uuid.UUID) (telemetry.Trip, error) {
+					return telemetry.Trip{}, telemetry.ErrNotFound
+				},
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "not found",
+		},
+		{
+			name:       "invalid id",
+			id:         "not-a-uuid",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid trip id",
+		},
+		{
+			name: "service error",
+			id:   id.String(),
+			svc: &mockService{
+				GetTripFunc: func(context.Context, uuid.UUID) (telemetry.Trip, error) {
+					return telemetry.Trip{}, errors.New("fail")
+				},
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal","detail":"internal error"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodGet, "/trips/"+tt.id, nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.GetTrip(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFindTripsHandler(t *testing.T) {
+	scooterID := uuid.New()
+	tests := []struct {
+		name       string
+		params     string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "happy path",
+			params: "?scooterId=" + scooterID.String() + "&clientId=rider-1&from=2024-01-01T00:00:00Z",
+			svc: &mockService{
+				FindTripsFunc: func(ctx context.Context, qry telemetry.TripQuery) ([]telemetry.Trip, error) {
+					if qry.ScooterID != scooterID || qry.ClientID != "rider-1" || qry.From.IsZero() {
+						return nil, errors.New("wrong params")
+					}
+					return []telemetry.Trip{{ScooterID: scooterID}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"scooterId":"` + scooterID.String() + `"`,
+		},
+		{
+			name:   "no trips",
+			params: "",
+			svc: &mockService{
+				FindTripsFunc: func(context.Context, telemetry.TripQuery) ([]telemetry.Trip, error) {
+					return nil, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+		{
+			name:       "invalid time",
+			params:     "?from=yesterday",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"from"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodGet, "/trips"+tt.params, nil)
+			w := httptest.NewRecorder()
+			h.FindTrips(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFindScooterEventsHandler(t *testing.T) {
+	scooterID := uuid.New()
+	tests := []struct {
+		name       string
+		id         string
+		params     string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:   "happy path",
+			id:     scooterID.String(),
+			params: "?type=location&from=2024-01-01T00:00:00Z&limit=10",
+			svc: &mockService{
+				FindEventsFunc: func(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error) {
+					if qry.ScooterID != scooterID || qry.Type != telemetry.EventLocation || qry.From.IsZero() || qry.Limit != 10 {
+						return nil, errors.New("wrong params")
+					}
+					return []telemetry.Event{{ScooterID: scooterID, Type: telemetry.EventLocation}}, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"type":"location"`,
+		},
+		{
+			name: "default limit",
+			id:   scooterID.String(),
+			svc: &mockService{
+				FindEventsFunc: func(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error) {
+					if qry.Limit != telemetry.DefaultEventLimit {
+						return nil, errors.New("wrong limit")
+					}
+					return nil, nil
+				},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[]",
+		},
+		{
+			name:       "invalid ID",
+			id:         "nope",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"code":"invalid_scooter_id"`,
+		},
+		{
+			name:       "invalid time",
+			id:         scooterID.String(),
+			params:     "?to=tomorrow",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"to"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters/"+tt.id+"/events"+tt.params, nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.FindScooterEvents(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestScooterTrackHandler(t *testing.T) {
+	scooterID := uuid.New()
+	track := telemetry.Track{
+		ScooterID: scooterID,
+		Points: []telemetry.TrackPoint{
+			{Point: telemetry.Point{Lat: 38.5, Lng: -120.2}},
+			{Point: telemetry.Point{Lat: 40.7, Lng: -120.95}},
+		},
+	}
+	svc := &mockService{
+		TrackFunc: func(ctx context.Context, qry telemetry.TrackQuery) (telemetry.Track, error) {
+			if qry.ScooterID != scooterID {
+				return telemetry.Track{}, errors.New("wrong params")
+			}
+			if qry.Tolerance < 0 {
+				return telemetry.Track{}, telemetry.ErrInvalidQuery
+			}
+			if !qry.To.IsZero() {
+				truncated := track
+				truncated.Truncated = true
+				return truncated, nil
+			}
+			return track, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		params      string
+		wantStatus  int
+		contentType string
+		wantBody    string
+		truncated   string
+	}{
+		{"default geojson", "", http.StatusOK, "application/geo+json", `"type":"LineString"`, ""},
+		{"gpx", "?format=gpx&from=2024-01-01T00:00:00Z", http.StatusOK, "application/gpx+xml", `<trkpt lat="40.7" lon="-120.95">`, ""},
+		{"polyline", "?format=polyline&tolerance=5", http.StatusOK, "application/json", `"polyline":"_p~iF~ps|U_ulLnnqC"`, ""},
+		{"truncated", "?format=polyline&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z", http.StatusOK, "application/json", `"truncated":true`, "true"},
+		{"unknown format", "?format=kml", http.StatusBadRequest, "application/problem+json", `"field":"format"`, ""},
+		{"invalid tolerance", "?tolerance=far", http.StatusBadRequest, "application/problem+json", `"field":"tolerance"`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters/"+scooterID.String()+"/track"+tt.params, nil)
+			r.SetPathValue("id", scooterID.String())
+			w := httptest.NewRecorder()
+			h.ScooterTrack(w, r)
+
+			if w.Code != tt.wantStatus || w.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("expected %d %s, got %d %s", tt.wantStatus, tt.contentType, w.Code, w.Header().Get("Content-Type"))
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+
+			if got := w.Header().Get("X-Track-Truncated"); got != tt.truncated {
+				t.Errorf("expected X-Track-Truncated %q, got %q", tt.truncated, got)
+			}
+		})
+	}
+}
+
+func TestFindScootersHandlerNegotiation(t *testing.T) {
+	id := uuid.New()
+	page := telemetry.ScooterPage{
+		Scooters: []telemetry.Scooter{{ID: id, Status: telemetry.StatusFree, Lat: 51.5, Lng: 17.5, Battery: 60}},
+		Next:     "abc",
+	}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{"no accept header", "", "application/json", `{"scooters":[{"id":"` + id.String() + `"`},
+		{"any", "*/*", "application/json", `{"scooters":[`},
+		{"geojson", "application/geo+json", "application/geo+json", `{"type":"FeatureCollection","features":[{"type":"Feature","id":"` + id.String() + `","geometry":{"type":"Point","coordinates":[17.5,51.5]},"properties":{"status":"free","battery":60,`},
+		{"geojson preferred", "application/json;q=0.5, application/geo+json", "application/geo+json", `"next":"abc"`},
+		{"json preferred", "application/geo+json;q=0.2, application/*", "application/json", `{"scooters":[`},
+		{"unsupported falls back to json", "text/html", "application/json", `{"scooters":[`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				FindScootersFunc: func(context.Context, telemetry.Query) (telemetry.ScooterPage, error) {
+					return page, nil
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters?minLat=51&minLng=17&maxLat=52&maxLng=18", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.FindScooters(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("expected content type %q, got %q", tt.wantContentType, got)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFindNearbyScootersHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       telemetry.NearbyQuery
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "defaults",
+			query:      "?lat=45.42&lng=-75.69",
+			want:       telemetry.NearbyQuery{Center: telemetry.Point{Lat: 45.42, Lng: -75.69}, Radius: telemetry.DefaultNearbyRadius, Limit: telemetry.DefaultNearbyLimit},
+			wantStatus: http.StatusOK,
+			wantBody:   `"distance":12.5`,
+		},
+		{
+			name:       "all parameters",
+			query:      "?lat=45.42&lng=-75.69&radius=250&limit=3&status=free&minBattery=20",
+			want:       telemetry.NearbyQuery{Center: telemetry.Point{Lat: 45.42, Lng: -75.69}, Radius: 250, Limit: 3, Status: telemetry.StatusFree, MinBattery: 20},
+			wantStatus: http.StatusOK,
+			wantBody:   `"distance":12.5`,
+		},
+		{
+			name:       "missing center",
+			query:      "?radius=250",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"lat"`,
+		},
+		{
+			name:       "radius too large",
+			query:      "?lat=45.42&lng=-75.69&radius=50000",
+			want:       telemetry.NearbyQuery{Center: telemetry.Point{Lat: 45.42, Lng: -75.69}, Radius: 50000, Limit: telemetry.DefaultNearbyLimit},
+			err:        fmt.Errorf("%w: radius must be between 0 and 5000 meters", telemetry.ErrInvalidQuery),
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "radius must be between",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				FindNearbyFunc: func(ctx context.Context, qry telemetry.NearbyQuery) ([]telemetry.NearbyScooter, error) {
+					if qry != tt.want {
+						return nil, fmt.Errorf("unexpected query %+v", qry)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return []telemetry.NearbyScooter{{Scooter: telemetry.Scooter{ID: uuid.New()}, Distance: 12.5}}, nil
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters/nearby"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.FindNearbyScooters(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestScooterGridHandler(t *testing.T) {
+	area := telemetry.Area{MinLat: 45.3, MinLng: -75.8, MaxLat: 45.5, MaxLng: -75.6}
+
+	tests := []struct {
+		name       string
+		query      string
+		want       telemetry.GridQuery
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "defaults",
+			query:      "?minLat=45.3&minLng=-75.8&maxLat=45.5&maxLng=-75.6",
+			want:       telemetry.GridQuery{Area: area, CellSize: telemetry.DefaultGridCellSize},
+			wantStatus: http.StatusOK,
+			wantBody:   `"counts":{"free":2}`,
+		},
+		{
+			name:       "all parameters",
+			query:      "?minLat=45.3&minLng=-75.8&maxLat=45.5&maxLng=-75.6&cellSize=0.05&status=free",
+			want:       telemetry.GridQuery{Area: area, CellSize: 0.05, Status: telemetry.StatusFree},
+			wantStatus: http.StatusOK,
+			wantBody:   `"total":2`,
+		},
+		{
+			name:       "invalid cell size",
+			query:      "?minLat=45.3&minLng=-75.8&maxLat=45.5&maxLng=-75.6&cellSize=big",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"cellSize"`,
+		},
+		{
+			name:       "NaN area",
+			query:      "?minLat=NaN&minLng=-75.8&maxLat=45.5&maxLng=-75.6",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"minLat","message":"must be a number"}]`,
+		},
+		{
+			name:       "missing area",
+			query:      "?cellSize=0.05",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `"errors":[{"field":"minLat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				ScooterGridFunc: func(ctx context.Context, qry telemetry.GridQuery) (telemetry.DensityGrid, error) {
+					if qry != tt.want {
+						return telemetry.DensityGrid{}, fmt.Errorf("unexpected query %+v", qry)
+					}
+					counts := []telemetry.CellCount{{Row: 1, Col: 2, Status: telemetry.StatusFree, Count: 2}}
+					return telemetry.NewDensityGrid(qry, counts), nil
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters/grid"+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ScooterGrid(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateScooterHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "happy path",
+			body: `{"lat":45.42,"lng":-75.69,"battery":80}`,
+			svc: &mockService{
+				CreateScooterFunc: func(ctx context.Context, s telemetry.Scooter) (telemetry.Scooter, error) {
+					s.Status = telemetry.StatusFree
+					s.GenCreateVals()
+					return s, nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"battery":80`,
+		},
+		{
+			name:       "unmarshal error",
+			body:       "not-json",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "unmarshalable request body",
+		},
+		{
+			name: "invalid scooter",
+			body: `{"lat":145,"lng":-75.69}`,
+			svc: &mockService{
+				CreateScooterFunc: func(context.Context, telemetry.Scooter) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, fmt.Errorf("%w: invalid coordinates", telemetry.ErrInvalidScooter)
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid coordinates",
+		},
+		{
+			name: "duplicate id",
+			body: `{"id":"` + uuid.NewString() + `","lat":45.42,"lng":-75.69}`,
+			svc: &mockService{
+				CreateScooterFunc: func(context.Context, telemetry.Scooter) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, telemetry.ErrAlreadyExists
+				},
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "already exists",
+		},
+		{
+			name: "not an operator",
+			body: `{"lat":45.42,"lng":-75.69}`,
+			svc: &mockService{
+				CreateScooterFunc: func(context.Context, telemetry.Scooter) (telemetry.Scooter, error) {
+					return telemetry.Scooter{}, telemetry.ErrOperatorOnly
+				},
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "operator credentials required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodPost, "/scooters", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			h.CreateScooter(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteScooterHandler(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name       string
+		id         string
+		err        error
+		wantStatus int
+	}{
+		{"happy path", id.String(), nil, http.StatusNoContent},
+		{"invalid id", "bad-id", nil, http.StatusBadRequest},
+		{"not found", id.String(), telemetry.ErrNotFound, http.StatusNotFound},
+		{"scooter in a ride", id.String(), telemetry.ErrInvalidTransition, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				DeleteScooterFunc: func(ctx context.Context, got uuid.UUID) error {
+					if got != id {
+						return errors.New("wrong id")
+					}
+					return tt.err
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodDelete, "/scooters/"+tt.id, nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			h.DeleteScooter(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestImportScootersHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		wantFormat  telemetry.FleetFormat
+		err         error
+		wantStatus  int
+		wantBody    string
+	}{
+		{"csv", "text/csv; charset=utf-8", telemetry.FormatCSV, nil, http.StatusOK, `"created":2`},
+		{"geojson", "application/geo+json", telemetry.FormatGeoJSON, nil, http.StatusOK, `"created":2`},
+		{"unsupported media type", "application/xml", "", nil, http.StatusUnsupportedMediaType, "unsupported fleet format"},
+		{"malformed file", "text/csv", telemetry.FormatCSV, fmt.Errorf("%w: missing \"lat\" column", telemetry.ErrInvalidImport), http.StatusBadRequest, "missing"},
+		{"not an operator", "text/csv", telemetry.FormatCSV, telemetry.ErrOperatorOnly, http.StatusForbidden, "operator credentials required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				ImportScootersFunc: func(ctx context.Context, format telemetry.FleetFormat, r io.Reader) (telemetry.ImportResult, error) {
+					if format != tt.wantFormat {
+						return telemetry.ImportResult{}, errors.New("wrong format")
+					}
+					return telemetry.ImportResult{Created: 2, Failed: 1, Errors: []telemetry.RowError{{Row: 3, Error: "invalid battery level"}}}, tt.err
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodPost, "/scooters/import", bytes.NewReader([]byte("lat,lng")))
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			h.ImportScooters(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportScootersHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		operator        bool
+		err             error
+		wantStatus      int
+		wantContentType string
+		wantAttachment  bool
+	}{
+		{"default csv", "", true, nil, http.StatusOK, "text/csv", true},
+		{"geojson", "?format=geojson", true, nil, http.StatusOK, "application/geo+json", true},
+		{"unknown format", "?format=xlsx", true, nil, http.StatusBadRequest, "application/problem+json", false},
+		{"not an operator", "", false, nil, http.StatusForbidden, "application/problem+json", false},
+		{"service error", "", true, errors.New("db down"), http.StatusInternalServerError, "application/problem+json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockService{
+				ExportScootersFunc: func(ctx context.Context, format telemetry.FleetFormat, w io.Writer) error {
+					if tt.err != nil {
+						return tt.err
+					}
+					return telemetry.WriteFleet(format, w, nil)
+				},
+			}
+
+			h := telemetry.NewHandler(svc)
+			r := httptest.NewRequest(http.MethodGet, "/scooters/export"+tt.query, nil)
+			if tt.operator {
+				r = r.WithContext(telemetry.WithOperator(r.Context()))
+			}
+			w := httptest.NewRecorder()
+			h.ExportScooters(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("expected content type %q, got %q", tt.wantContentType, got)
+			}
+
+			if got := w.Header().Get("Content-Disposition") != ""; got != tt.wantAttachment {
+				t.Errorf("expected attachment %v, got Content-Disposition %q", tt.wantAttachment, w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestCreateZoneHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		svc        *mockService
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "happy path",
+			body: `{"name":"Old Port","rule":"no_parking","polygon":[{"lat":45.50,"lng":-73.56},{"lat":45.51,"lng":-73.56},{"lat":45.51,"lng":-73.55}],"active":true}`,
+			svc: &mockService{
+				CreateZoneFunc: func(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error) {
+					if z.Name != "Old Port" || z.Rule != telemetry.RuleNoParking || len(z.Polygon) != 3 {
+						return telemetry.Zone{}, errors.New("wrong zone")
+					}
+					z.GenCreateVals()
+					return z, nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"name":"Old Port"`,
+		},
+		{
+			name: "active by default",
+			body: `{"name":"Old Port","rule":"no_parking","polygon":[{"lat":45.50,"lng":-73.56},{"lat":45.51,"lng":-73.56},{"lat":45.51,"lng":-73.55}]}`,
+			svc: &mockService{
+				CreateZoneFunc: func(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error) {
+					if !z.Active {
+						return telemetry.Zone{}, errors.New("inactive zone")
+					}
+					return z, nil
+				},
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"active":true`,
+		},
+		{
+			name:       "unmarshal error",
+			body:       "not-json",
+			svc:        &mockService{},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "unmarshalable request body",
+		},
+		{
+			name: "invalid zone",
+			body: `{"name":"Old Port","rule":"no_parking","polygon":[]}`,
+			svc: &mockService{
+				CreateZoneFunc: func(context.Context, telemetry.Zone) (telemetry.Zone, error) {
+					return telemetry.Zone{}, telemetry.ErrInvalidZone
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid zone",
+		},
+		{
+			name: "not an operator",
+			body: `{"name":"Old Port"}`,
+			svc: &mockService{
+				CreateZoneFunc: func(context.Context, telemetry.Zone) (telemetry.Zone, error) {
+					return telemetry.Zone{}, telemetry.ErrOperatorOnly
+				},
+			},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "operator credentials required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := telemetry.NewHandler(tt.svc)
+			r := httptest.NewRequest(http.MethodPost, "/zones", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			h.CreateZone(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			if !bytes.Contains(w.Body.Bytes(), []byte(tt.wantBody)) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFindTripViolationsHandler(t *testing.T) {
+	tripID := uuid.New()
+	svc := &mockService{
+		FindViolationsFunc: func(ctx context.Context, qry telemetry.ViolationQuery) ([]telemetry.Violation, error) {
+			if qry.TripID != tripID {
+				return nil, nil
+			}
+			return []telemetry.Violation{{TripID: &tripID, Rule: telemetry.RuleNoParking}}, nil
+		},
+	}
+
+	h := telemetry.NewHandler(svc)
+	r := httptest.NewRequest(http.MethodGet, "/trips/"+tripID.String()+"/violations", nil)
+	r.SetPathValue("id", tripID.String())
+	w := httptest.NewRecorder()
+	h.FindTripViolations(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if want := `"rule":"no_parking"`; !bytes.Contains(w.Body.Bytes(), []byte(want)) {
+		t.Errorf("expected body to contain %q, got %q", want, w.Body.String())
+	}
+}
+
+type mockService struct {
+	GetScooterFunc     func(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error)
+	CreateScooterFunc  func(ctx context.Context, s telemetry.Scooter) (telemetry.Scooter, error)
+	UpdateScooterFunc  func(ctx context.Context, p telemetry.ScooterPatch) (telemetry.Scooter, error)
+	DeleteScooterFunc  func(ctx context.Context, id uuid.UUID) error
+	ImportScootersFunc func(ctx context.Context, format telemetry.FleetFormat, r io.Reader) (telemetry.ImportResult, error)
+	ExportScootersFunc func(ctx context.Context, format telemetry.FleetFormat, w io.Writer) error
+	FindScootersFunc   func(ctx context.Context, qry telemetry.Query) (telemetry.ScooterPage, error)
+	FindNearbyFunc     func(ctx context.Context, qry telemetry.NearbyQuery) ([]telemetry.NearbyScooter, error)
+	ScooterGridFunc    func(ctx context.Context, qry telemetry.GridQuery) (telemetry.DensityGrid, error)
+	ReportEventFunc    func(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error)
+	ReportEventsFunc   func(ctx context.Context, events []telemetry.Event) ([]telemetry.BatchItem, error)
+	FindEventsFunc     func(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error)
+	TrackFunc          func(ctx context.Context, qry telemetry.TrackQuery) (telemetry.Track, error)
+	ChangeStatusFunc   func(ctx context.Context, change telemetry.StatusChange) (telemetry.Scooter, error)
+	ReserveScooterFunc func(ctx context.Context, id uuid.UUID) (telemetry.Reservation, error)
+	GetTripFunc        func(ctx context.Context, id uuid.UUID) (telemetry.Trip, error)
+	FindTripsFunc      func(ctx context.Context, qry telemetry.TripQuery) ([]telemetry.Trip, error)
+	CreateZoneFunc     func(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error)
+	UpdateZoneFunc     func(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error)
+	GetZoneFunc        func(ctx context.Context, id uuid.UUID) (telemetry.Zone, error)
+	DeleteZoneFunc     func(ctx context.Context, id uuid.UUID) error
+	ListZonesFunc      func(ctx context.Context) ([]telemetry.Zone, error)
+	FindViolationsFunc func(ctx context.Context, qry telemetry.ViolationQuery) ([]telemetry.Violation, error)
+	WatchScootersFunc  func(ctx context.Context, qry telemetry.StreamQuery) (*telemetry.Subscription, error)
+}
+
+func (m *mockService) GetScooter(ctx context.Context, id uuid.UUID) (telemetry.Scooter, error) {
+	return m.GetScooterFunc(ctx, id)
+}
+
+func (m *mockService) CreateScooter(ctx context.Context, s telemetry.Scooter) (telemetry.Scooter, error) {
+	return m.CreateScooterFunc(ctx, s)
+}
+
+func (m *mockService) DeleteScooter(ctx context.Context, id uuid.UUID) error {
+	return m.DeleteScooterFunc(ctx, id)
+}
+
+func (m *mockService) ImportScooters(ctx context.Context, format telemetry.FleetFormat, r io.Reader) (telemetry.ImportResult, error) {
+	return m.ImportScootersFunc(ctx, format, r)
+}
+
+func (m *mockService) ExportScooters(ctx context.Context, format telemetry.FleetFormat, w io.Writer) error {
+	return m.ExportScootersFunc(ctx, format, w)
+}
+
+func (m *mockService) ScooterGrid(ctx context.Context, qry telemetry.GridQuery) (telemetry.DensityGrid, error) {
+	return m.ScooterGridFunc(ctx, qry)
+}
+
+func (m *mockService) FindNearbyScooters(ctx context.Context, qry telemetry.NearbyQuery) ([]telemetry.NearbyScooter, error) {
+	return m.FindNearbyFunc(ctx, qry)
+}
+
+func (m *mockService) UpdateScooter(ctx context.Context, p telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return m.UpdateScooterFunc(ctx, p)
+}
+
+func (m *mockService) FindScooters(ctx context.Context, qry telemetry.Query) (telemetry.ScooterPage, error) {
+	return m.FindScootersFunc(ctx, qry)
+}
+
+func (m *mockService) ReportEvent(ctx context.Context, e telemetry.Event) (telemetry.EventResult, error) {
+	if m.ReportEventFunc != nil {
+		return m.ReportEventFunc(ctx, e)
+	}
+	return telemetry.EventResult{}, nil
+}
+
+func (m *mockService) ReportEvents(ctx context.Context, events []telemetry.Event) ([]telemetry.BatchItem, error) {
+	return m.ReportEventsFunc(ctx, events)
+}
+
+func (m *mockService) FindEvents(ctx context.Context, qry telemetry.EventQuery) ([]telemetry.Event, error) {
+	return m.FindEventsFunc(ctx, qry)
+}
+
+func (m *mockService) Track(ctx context.Context, qry telemetry.TrackQuery) (telemetry.Track, error) {
+	return m.TrackFunc(ctx, qry)
+}
+
+func (m *mockService) ChangeStatus(ctx context.Context, change telemetry.StatusChange) (telemetry.Scooter, error) {
+	return m.ChangeStatusFunc(ctx, change)
+}
+
+func (m *mockService) ReserveScooter(ctx context.Context, id uuid.UUID) (telemetry.Reservation, error) {
+	return m.ReserveScooterFunc(ctx, id)
+}
+
+func (m *mockService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockService) WatchScooters(ctx context.Context, qry telemetry.StreamQuery) (*telemetry.Subscription, error) {
+	return m.WatchScootersFunc(ctx, qry)
+}
+
+func (m *mockService) PurgeProcessedEvents(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockService) GetTrip(ctx context.Context, id uuid.UUID) (telemetry.Trip, error) {
+	return m.GetTripFunc(ctx, id)
+}
+
+func (m *mockService) FindTrips(ctx context.Context, qry telemetry.TripQuery) ([]telemetry.Trip, error) {
+	return m.FindTripsFunc(ctx, qry)
+}
+
+func (m *mockService) CreateZone(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error) {
+	return m.CreateZoneFunc(ctx, z)
+}
+
+func (m *mockService) UpdateZone(ctx context.Context, z telemetry.Zone) (telemetry.Zone, error) {
+	return m.UpdateZoneFunc(ctx, z)
+}
+
+func (m *mockService) GetZone(ctx context.Context, id uuid.UUID) (telemetry.Zone, error) {
+	return m.GetZoneFunc(ctx, id)
+}
+
+func (m *mockService) DeleteZone(ctx context.Context, id uuid.UUID) error {
+	return m.DeleteZoneFunc(ctx, id)
+}
+
+func (m *mockService) ListZones(ctx context.Context) ([]telemetry.Zone, error) {
+	return m.ListZonesFunc(ctx)
+}
+
+func (m *mockService) FindViolations(ctx context.Context, qry telemetry.ViolationQuery) ([]telemetry.Violation, error) {
+	return m.FindViolationsFunc(ctx, qry)
+}
+
+func happyGetScooter(expectedID uuid.UUID) func(context.Context, uuid.UUID) (telemetry.Scooter, error) {
+	return func(ctx context.Context, gotID uuid.UUID) (telemetry.Scooter, error) {
+		if gotID != expectedID {
+			return telemetry.Scooter{}, errors.New("wrong id")
+		}
+		return telemetry.Scooter{ID: expectedID, Status: telemetry.StatusFree, Version: 3}, nil
+	}
+}
+
+func notFoundGetScooter(context.Context, uuid.UUID) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, telemetry.ErrNotFound
+}
+
+func failGetScooter(context.Context, uuid.UUID) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, errors.New("fail")
+}
+
+func alwaysNilGetScooter(context.Context, uuid.UUID) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, nil
+}
+
+func happyUpdateScooter(expected telemetry.ScooterPatch) func(context.Context, telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return func(ctx context.Context, p telemetry.ScooterPatch) (telemetry.Scooter, error) {
+		if p.ScooterID != expected.ScooterID || p.Version != expected.Version ||
+			p.Lat != nil || p.Lng != nil || p.Battery == nil || *p.Battery != *expected.Battery {
+			return telemetry.Scooter{}, errors.New("wrong patch")
+		}
+		return telemetry.Scooter{ID: p.ScooterID, Battery: *p.Battery, Version: 4}, nil
+	}
+}
+
+func alwaysNilUpdateScooter(context.Context, telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, nil
+}
+
+func conflictUpdateScooter(context.Context, telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, telemetry.ErrVersionConflict
+}
+
+func notFoundUpdateScooter(context.Context, telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, telemetry.ErrNotFound
 }
 
-func failUpdateScooter(context.Context, telemetry.Scooter) error {
-	return errors.New("fail")
+func failUpdateScooter(context.Context, telemetry.ScooterPatch) (telemetry.Scooter, error) {
+	return telemetry.Scooter{}, errors.New("fail")
 }
 
-func happyFindScooters(expectedQuery telemetry.Query, result []telemetry.Scooter) func(context.Context, telemetry.Query) ([]telemetry.Scooter, error) {
-	return func(ctx context.Context, gotQuery telemetry.Query) ([]telemetry.Scooter, error) {
+func happyFindScooters(expectedQuery telemetry.Query, result telemetry.ScooterPage) func(context.Context, telemetry.Query) (telemetry.ScooterPage, error) {
+	return func(ctx context.Context, gotQuery telemetry.Query) (telemetry.ScooterPage, error) {
 		if gotQuery != expectedQuery {
-			return nil, errors.New("wrong params")
+			return telemetry.ScooterPage{}, errors.New("wrong params")
 		}
 		return result, nil
 	}
 }
 
-func alwaysNilFindScooters(context.Context, telemetry.Query) ([]telemetry.Scooter, error) {
-	return nil, nil
+func alwaysNilFindScooters(context.Context, telemetry.Query) (telemetry.ScooterPage, error) {
+	return telemetry.ScooterPage{}, nil
 }
 
-func failFindScooters(context.Context, telemetry.Query) ([]telemetry.Scooter, error) {
-	return nil, errors.New("fail")
+func failFindScooters(context.Context, telemetry.Query) (telemetry.ScooterPage, error) {
+	return telemetry.ScooterPage{}, errors.New("fail")
 }
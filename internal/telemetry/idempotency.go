@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultDedupWindow is how long a reported event can be retried and get
+	// its original result back.
+	DefaultDedupWindow = 24 * time.Hour
+	// DedupSweepInterval is how often processed events older than the dedup
+	// window are purged.
+	DedupSweepInterval = 10 * time.Minute
+)
+
+// maxIdempotencyKeyLen bounds the idempotency keys accepted from callers.
+const maxIdempotencyKeyLen = 255
+
+var (
+	ErrInvalidIdempotencyKey = newError(KindInvalid, "invalid_idempotency_key", "invalid idempotency key")
+	ErrIdempotencyKeyReused  = newError(KindConflict, "idempotency_key_reused", "idempotency key already used for a different event")
+)
+
+// ProcessedEvent is the result of an accepted event kept under its
+// idempotency key, so that a retry within the dedup window gets the same
+// result without the event being applied again. Keys are scoped by client.
+type ProcessedEvent struct {
+	ClientID  string
+	Key       string
+	ScooterID uuid.UUID
+	Type      EventType
+	Result    EventResult
+	CreatedAt time.Time
+}
+
+// matches reports whether e can be a retry of the processed event.
+func (p ProcessedEvent) matches(e Event) bool {
+	return p.ScooterID == e.ScooterID && p.Type == e.Type
+}
+
+// idempotencyKeyHeader reads the Idempotency-Key request header. A missing
+// header is not an error.
+func idempotencyKeyHeader(r *http.Request) (string, error) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		return "", nil
+	}
+
+	if len(key) > maxIdempotencyKeyLen || !isPrintable(key) {
+		return "", invalidField(ErrInvalidIdempotencyKey, "Idempotency-Key", "must be up to 255 printable characters")
+	}
+
+	return key, nil
+}
+
+// eventKey returns the key an event is deduplicated by: the idempotency key
+// of the request or, without one, the ID the client gave the event. Events
+// with neither are not deduplicated.
+func (s *service) eventKey(ctx context.Context, e Event) string {
+	if s.dedupWindow <= 0 {
+		return ""
+	}
+
+	if key, ok := IdempotencyKey(ctx); ok && key != "" {
+		return key
+	}
+
+	if e.ID != uuid.Nil {
+		return e.ID.String()
+	}
+
+	return ""
+}
+
+// replay returns the result of the event already processed under key within
+// the dedup window, if any.
+func (s *service) replay(ctx context.Context, key string, e Event) (EventResult, bool, error) {
+	clientID, _ := ClientID(ctx)
+	since := time.Now().Add(-s.dedupWindow)
+
+	p, err := s.repo.GetProcessedEvent(ctx, clientID, key, since)
+	if errors.Is(err, ErrNotFound) {
+		return EventResult{}, false, nil
+	}
+
+	if err != nil {
+		return EventResult{}, false, err
+	}
+
+	if !p.matches(e) {
+		return EventResult{}, false, ErrIdempotencyKeyReused
+	}
+
+	res := p.Result
+	res.Replayed = true
+	return res, true, nil
+}
+
+// remember keeps the result of an applied event under key. The event has
+// already changed the scooter at this point, so a failure is only logged:
+// reporting it would make the client retry an event that went through.
+func (s *service) remember(ctx context.Context, key string, e Event, res EventResult) {
+	clientID, _ := ClientID(ctx)
+
+	p := ProcessedEvent{
+		ClientID:  clientID,
+		Key:       key,
+		ScooterID: e.ScooterID,
+		Type:      e.Type,
+		Result:    res,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.repo.SaveProcessedEvent(ctx, p)
+	if err != nil {
+		log.Printf("idempotency: cannot save result of event %s: %v", e.ID, err)
+	}
+}
+
+// PurgeProcessedEvents drops the processed events older than the dedup
+// window and returns how many were dropped.
+func (s *service) PurgeProcessedEvents(ctx context.Context) (int, error) {
+	return s.repo.DeleteProcessedEvents(ctx, time.Now().Add(-s.dedupWindow))
+}
+
+// SweepProcessedEvents purges expired processed events every interval until
+// ctx is done.
+func SweepProcessedEvents(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.PurgeProcessedEvents(ctx)
+			if err != nil {
+				log.Printf("dedup sweeper error: %v", err)
+				continue
+			}
+
+			if n > 0 {
+				log.Printf("dedup sweeper: purged %d processed events", n)
+			}
+		}
+	}
+}
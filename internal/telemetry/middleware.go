@@ -4,38 +4,97 @@ import (
 	"context"
 	"log"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 type contextKey string
 
-const clientIDKey contextKey = "clientID"
+var (
+	ErrInvalidAPIKey = newError(KindUnauthorized, "invalid_api_key", "invalid API key")
+	ErrOperatorOnly  = newError(KindForbidden, "operator_only", "operator credentials required")
+)
+
+const (
+	clientIDKey  contextKey = "clientID"
+	operatorKey  contextKey = "operator"
+	requestIDKey contextKey = "requestID"
+	idemKeyKey   contextKey = "idempotencyKey"
+)
+
+// maxRequestIDLen bounds the request IDs accepted from callers.
+const maxRequestIDLen = 128
 
-func AuthMiddleware(validAPIKeys []string) func(http.Handler) http.Handler {
+// AuthMiddleware accepts requests carrying one of the client or operator API
+// keys. Requests authenticated with an operator key are flagged so that
+// operator-only operations can be authorized downstream.
+func AuthMiddleware(validAPIKeys []string, operatorAPIKeys ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			apiKey := r.Header.Get("X-API-Key")
 			clientID := r.Header.Get("X-Client-ID")
 
-			valid := false
-			for _, k := range validAPIKeys {
-				if apiKey == k {
-					valid = true
-					break
-				}
-			}
+			operator := apiKey != "" && contains(operatorAPIKeys, apiKey)
+			valid := operator || contains(validAPIKeys, apiKey)
 
 			if !valid {
 				log.Printf("auth: invalid API key: %q, client: %q, path: %s", mask(apiKey), clientID, r.URL.Path)
-				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				writeProblem(w, r, ErrInvalidAPIKey)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), clientIDKey, clientID)
+			ctx := WithClientID(r.Context(), clientID)
+			if operator {
+				ctx = WithOperator(ctx)
+			}
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// RequestIDMiddleware tags every request with an ID, echoed in the
+// X-Request-ID response header and in error responses so that a failure seen
+// by a client can be found in the logs. A well-formed ID sent by the caller is
+// kept, otherwise a new one is generated.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !isValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+func isValidRequestID(id string) bool {
+	return id != "" && len(id) <= maxRequestIDLen && isPrintable(id)
+}
+
+// isPrintable reports whether s only holds printable ASCII characters other
+// than space.
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if key == k {
+			return true
+		}
+	}
+
+	return false
+}
+
 func mask(s string) string {
 	if len(s) > 4 {
 		return s[:2] + "..." + s[len(s)-2:]
@@ -44,7 +103,46 @@ func mask(s string) string {
 	return "[redacted]"
 }
 
+// WithClientID returns a copy of ctx carrying the caller client ID.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey, clientID)
+}
+
 func ClientID(ctx context.Context) (string, bool) {
 	clientID, ok := ctx.Value(clientIDKey).(string)
 	return clientID, ok
 }
+
+// WithOperator returns a copy of ctx flagged as authenticated with operator
+// credentials.
+func WithOperator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorKey, true)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
+
+// WithIdempotencyKey returns a copy of ctx carrying the idempotency key the
+// caller sent with the request.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idemKeyKey, key)
+}
+
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idemKeyKey).(string)
+	return key, ok
+}
+
+// IsOperator reports whether the request was authenticated with operator
+// credentials.
+func IsOperator(ctx context.Context) bool {
+	operator, _ := ctx.Value(operatorKey).(bool)
+	return operator
+}
@@ -8,9 +8,12 @@ import (
 
 func TestAuthMiddleware(t *testing.T) {
 	validKeys := []string{"demo-api-key"}
-	auth := AuthMiddleware(validKeys)
+	auth := AuthMiddleware(validKeys, "operator-key")
 
 	dummyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if IsOperator(r.Context()) {
+			w.Header().Set("X-Operator", "true")
+		}
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok")) // ignore error for test
 	})
@@ -18,14 +21,16 @@ func TestAuthMiddleware(t *testing.T) {
 	handler := auth(dummyHandler)
 
 	tests := []struct {
-		name       string
-		apiKey     string
-		clientID   string
-		wantStatus int
+		name         string
+		apiKey       string
+		clientID     string
+		wantStatus   int
+		wantOperator bool
 	}{
-		{"valid key", "demo-api-key", "test-client", http.StatusOK},
-		{"invalid key", "bad-key", "test-client", http.StatusUnauthorized},
-		{"missing key", "", "test-client", http.StatusUnauthorized},
+		{"valid key", "demo-api-key", "test-client", http.StatusOK, false},
+		{"operator key", "operator-key", "ops", http.StatusOK, true},
+		{"invalid key", "bad-key", "test-client", http.StatusUnauthorized, false},
+		{"missing key", "", "test-client", http.StatusUnauthorized, false},
 	}
 
 	for _, tt := range tests {
@@ -45,6 +50,52 @@ func TestAuthMiddleware(t *testing.T) {
 			if rr.Code != tt.wantStatus {
 				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatus)
 			}
+
+			if tt.wantStatus == http.StatusUnauthorized && rr.Header().Get("Content-Type") != mediaTypeProblem {
+				t.Errorf("got content type %q, want %q", rr.Header().Get("Content-Type"), mediaTypeProblem)
+			}
+
+			if got := rr.Header().Get("X-Operator") == "true"; got != tt.wantOperator {
+				t.Errorf("got operator %v, want %v", got, tt.wantOperator)
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := RequestID(r.Context())
+		_, _ = w.Write([]byte(id))
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool
+	}{
+		{"caller id kept", "3f2a-req", true},
+		{"missing id generated", "", false},
+		{"malformed id replaced", "bad id\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			got := rr.Header().Get("X-Request-ID")
+			if got == "" || got != rr.Body.String() {
+				t.Fatalf("expected response header to match context ID, got %q and %q", got, rr.Body.String())
+			}
+
+			if (got == tt.header) != tt.wantSame {
+				t.Errorf("got request ID %q for header %q", got, tt.header)
+			}
 		})
 	}
 }
@@ -1,6 +1,9 @@
 package telemetry
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,16 +12,44 @@ import (
 type Status string
 
 const (
-	StatusFree     Status = "free"
-	StatusOccupied Status = "occupied"
+	StatusFree           Status = "free"
+	StatusOccupied       Status = "occupied"
+	StatusReserved       Status = "reserved"
+	StatusMaintenance    Status = "maintenance"
+	StatusOffline        Status = "offline"
+	StatusLowBattery     Status = "low_battery"
+	StatusDecommissioned Status = "decommissioned"
 )
 
+func IsValidStatus(s Status) bool {
+	switch s {
+	case StatusFree, StatusOccupied, StatusReserved, StatusMaintenance,
+		StatusOffline, StatusLowBattery, StatusDecommissioned:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// DefaultLowBatteryThreshold is the battery percentage below which an idle
+// scooter is taken out of rental.
+const DefaultLowBatteryThreshold = 15
+
 type Scooter struct {
 	ID        uuid.UUID `json:"id"`
 	Status    Status    `json:"status"`
 	Lat       float64   `json:"lat"`
 	Lng       float64   `json:"lng"`
+	Battery   int       `json:"battery"` // percentage
 	UpdatedAt time.Time `json:"updatedAt"`
+	Version   int       `json:"version"` // bumped on every stored change
+	// LastEventAt is the device time of the last location or battery report
+	// applied to the scooter.
+	LastEventAt *time.Time `json:"lastEventAt,omitempty"`
+	// LocatedAt is the device time of the last location report applied to
+	// the scooter, the time of its current position.
+	LocatedAt *time.Time `json:"-"`
 }
 
 func (s *Scooter) GenID() {
@@ -37,12 +68,136 @@ func (s *Scooter) StopRide() {
 	s.AuditUpdate()
 }
 
+// Apply applies the event to the scooter enforcing the lifecycle state machine.
+// Events that are not allowed in the current status leave the scooter untouched
+// and return ErrInvalidTransition.
+func (s *Scooter) Apply(e Event) error {
+	_, err := s.NextStatus(e.Type)
+	if err != nil {
+		return err
+	}
+
+	switch e.Type {
+	case EventTripStart:
+		s.StartRide()
+	case EventTripEnd:
+		s.StopRide()
+	case EventLocation:
+		s.UpdateLocation(e.Lat, e.Lng)
+	case EventBattery:
+		s.UpdateBattery(*e.Battery)
+	}
+
+	if e.Type.IsReport() {
+		at := e.OccurredAt
+		s.LastEventAt = &at
+	}
+
+	if e.Type == EventLocation {
+		at := e.OccurredAt
+		s.LocatedAt = &at
+	}
+
+	return nil
+}
+
+// IsStale reports whether e is a location or battery report that occurred
+// before the last one applied, e.g. delivered late by a scooter that was
+// offline. Applying it would roll the scooter state back. Ride events are
+// never stale: they come from the rider's device, whose clock cannot be
+// compared with the scooter's, and are applied in the order they arrive.
+func (s *Scooter) IsStale(e Event) bool {
+	return e.Type.IsReport() && s.LastEventAt != nil && e.OccurredAt.Before(*s.LastEventAt)
+}
+
+// NextStatus returns the status the scooter would move to if an event of the
+// given type were applied.
+func (s *Scooter) NextStatus(t EventType) (Status, error) {
+	next, ok := transitions[s.Status][t]
+	if !ok {
+		return s.Status, fmt.Errorf("%w: %s on %s scooter", ErrInvalidTransition, t, s.Status)
+	}
+
+	return next, nil
+}
+
 func (s *Scooter) UpdateLocation(lat, lng float64) {
 	s.Lat = lat
 	s.Lng = lng
 	s.AuditUpdate()
 }
 
+func (s *Scooter) UpdateBattery(level int) {
+	s.Battery = level
+	s.AuditUpdate()
+}
+
+// CheckBattery moves an idle scooter out of rental when its battery drops
+// below the threshold and back to free once it has been recharged. Occupied
+// scooters keep riding and are checked again when the trip ends.
+func (s *Scooter) CheckBattery(threshold int) {
+	switch {
+	case s.Status == StatusFree && s.Battery < threshold:
+		s.Status = StatusLowBattery
+		s.AuditUpdate()
+	case s.Status == StatusLowBattery && s.Battery >= threshold:
+		s.Status = StatusFree
+		s.AuditUpdate()
+	}
+}
+
+// Reserve holds a free scooter for a rider.
+func (s *Scooter) Reserve() error {
+	if s.Status != StatusFree {
+		return fmt.Errorf("%w: reserve %s scooter", ErrInvalidTransition, s.Status)
+	}
+
+	s.Status = StatusReserved
+	s.AuditUpdate()
+	return nil
+}
+
+// Release frees a reserved scooter. Scooters in any other status are left
+// untouched.
+func (s *Scooter) Release() {
+	if s.Status != StatusReserved {
+		return
+	}
+
+	s.Status = StatusFree
+	s.AuditUpdate()
+}
+
+// SetStatus applies an operator status change following operatorTransitions.
+func (s *Scooter) SetStatus(to Status) error {
+	for _, allowed := range operatorTransitions[s.Status] {
+		if allowed == to {
+			s.Status = to
+			s.AuditUpdate()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, s.Status, to)
+}
+
+// Decommission retires the scooter from the fleet. A scooter cannot be
+// decommissioned in the middle of a ride.
+func (s *Scooter) Decommission() error {
+	if s.Status == StatusOccupied {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, s.Status, StatusDecommissioned)
+	}
+
+	s.Status = StatusDecommissioned
+	s.AuditUpdate()
+	return nil
+}
+
+// Location returns the current scooter position.
+func (s *Scooter) Location() Point {
+	return Point{Lat: s.Lat, Lng: s.Lng}
+}
+
 func (s *Scooter) AuditUpdate() {
 	s.UpdatedAt = time.Now()
 }
@@ -50,6 +205,40 @@ func (s *Scooter) AuditUpdate() {
 func (s *Scooter) GenCreateVals() {
 	s.GenID()
 	s.UpdatedAt = time.Now()
+	s.Version = 1
+}
+
+// ETag returns the scooter version as a strong entity tag.
+func (s *Scooter) ETag() string {
+	return `"` + strconv.Itoa(s.Version) + `"`
+}
+
+// ScooterPatch is a partial scooter update: only the fields that are set are
+// changed. Version, when not zero, is the version the change was based on and
+// the update fails with ErrVersionConflict if the scooter has moved on since.
+type ScooterPatch struct {
+	ScooterID uuid.UUID `json:"-"`
+	Version   int       `json:"-"`
+	Lat       *float64  `json:"lat,omitempty"`
+	Lng       *float64  `json:"lng,omitempty"`
+	Battery   *int      `json:"battery,omitempty"`
+}
+
+// Patch applies the fields set in p to the scooter.
+func (s *Scooter) Patch(p ScooterPatch) {
+	if p.Lat != nil {
+		s.Lat = *p.Lat
+	}
+
+	if p.Lng != nil {
+		s.Lng = *p.Lng
+	}
+
+	if p.Battery != nil {
+		s.Battery = *p.Battery
+	}
+
+	s.AuditUpdate()
 }
 
 type EventType string
@@ -58,15 +247,95 @@ const (
 	EventTripStart EventType = "trip_start"
 	EventTripEnd   EventType = "trip_end"
 	EventLocation  EventType = "location"
+	EventBattery   EventType = "battery"
 )
 
+// IsReport reports whether events of the type report the scooter state rather
+// than drive a ride.
+func (t EventType) IsReport() bool {
+	return t == EventLocation || t == EventBattery
+}
+
+var ErrInvalidTransition = newError(KindConflict, "invalid_transition", "invalid status transition")
+
+// transitions is the scooter lifecycle state machine: for each status it lists
+// the accepted event types and the status they lead to. Any pair not listed is
+// an illegal transition (e.g. a second trip_start on an occupied scooter).
+var transitions = map[Status]map[EventType]Status{
+	StatusFree: {
+		EventTripStart: StatusOccupied,
+		EventBattery:   StatusFree,
+	},
+	StatusOccupied: {
+		EventLocation: StatusOccupied,
+		EventTripEnd:  StatusFree,
+		EventBattery:  StatusOccupied,
+	},
+	StatusReserved: {
+		EventTripStart: StatusOccupied,
+		EventBattery:   StatusReserved,
+	},
+	StatusLowBattery: {
+		EventBattery: StatusLowBattery,
+	},
+	StatusMaintenance: {
+		EventBattery: StatusMaintenance,
+	},
+	StatusOffline: {
+		EventBattery: StatusOffline,
+	},
+}
+
+// operatorTransitions lists the status changes the fleet operations team can
+// make. Occupied scooters cannot be pulled mid-ride and decommissioned is
+// terminal.
+var operatorTransitions = map[Status][]Status{
+	StatusFree:        {StatusMaintenance, StatusOffline, StatusDecommissioned},
+	StatusLowBattery:  {StatusMaintenance, StatusOffline, StatusDecommissioned},
+	StatusReserved:    {StatusFree, StatusMaintenance, StatusOffline},
+	StatusMaintenance: {StatusFree, StatusOffline, StatusDecommissioned},
+	StatusOffline:     {StatusFree, StatusMaintenance, StatusDecommissioned},
+}
+
+// DefaultMaxClockSkew is how far ahead of the server clock a device clock may
+// run before its events are rejected.
+const DefaultMaxClockSkew = time.Minute
+
+// Event is reported by a scooter or a rider device. OccurredAt is the device
+// time the event happened at and ReceivedAt the server time it was accepted
+// at. Stale is set on reports that arrived after a newer one and were only
+// recorded.
 type Event struct {
-	ID        uuid.UUID `json:"id"`
-	ScooterID uuid.UUID `json:"scooterId"`
-	Type      EventType `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
-	Lat       float64   `json:"lat"`
-	Lng       float64   `json:"lng"`
+	ID         uuid.UUID `json:"id"`
+	ScooterID  uuid.UUID `json:"scooterId"`
+	Type       EventType `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	Lat        float64   `json:"lat"`
+	Lng        float64   `json:"lng"`
+	Battery    *int      `json:"battery,omitempty"` // percentage, battery events only
+	Stale      bool      `json:"stale,omitempty"`
+}
+
+// UnmarshalJSON reads the deprecated timestamp field as occurredAt for devices
+// that still send it; occurredAt wins when both are set.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	aux := struct {
+		*event
+		Timestamp *time.Time `json:"timestamp"`
+	}{event: (*event)(e)}
+
+	err := json.Unmarshal(data, &aux)
+	if err != nil {
+		return err
+	}
+
+	if aux.Timestamp != nil && e.OccurredAt.IsZero() {
+		e.OccurredAt = *aux.Timestamp
+	}
+
+	return nil
 }
 
 func (e *Event) GenID() {
@@ -75,9 +344,95 @@ func (e *Event) GenID() {
 	}
 }
 
+// GenCreateVals sets the ID and the reception time. An event reported without
+// its device time is taken as occurring when it was received.
 func (e *Event) GenCreateVals() {
 	e.GenID()
-	e.Timestamp = time.Now()
+	e.ReceivedAt = time.Now()
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = e.ReceivedAt
+	}
+}
+
+// CheckClockSkew rejects events that occurred later than maxSkew after they
+// were received: the device clock is off and its time cannot be trusted to
+// order the event.
+func (e *Event) CheckClockSkew(maxSkew time.Duration) error {
+	if e.OccurredAt.Sub(e.ReceivedAt) > maxSkew {
+		return invalidField(ErrInvalidEvent, "occurredAt", "is in the future beyond the tolerated clock skew")
+	}
+
+	return nil
+}
+
+// ClampRideTime bounds the device time of a trip_start or trip_end to maxSkew
+// before the event was received. Ride events are applied in the order they
+// arrive, so an older device time can only come from a wrong or tampered
+// clock and is replaced with the reception time.
+func (e *Event) ClampRideTime(maxSkew time.Duration) {
+	if e.Type.IsReport() {
+		return
+	}
+
+	if e.ReceivedAt.Sub(e.OccurredAt) > maxSkew {
+		e.OccurredAt = e.ReceivedAt
+	}
+}
+
+// EventResult is returned to the reporter of an accepted event. Trip is only
+// set on trip_end and carries the closed ride with its fare. Stale is set when
+// the event was recorded but not applied because a newer report had already
+// been. Replayed is set when the event had already been processed and the
+// original result is returned.
+type EventResult struct {
+	EventID   uuid.UUID `json:"eventId"`
+	ScooterID uuid.UUID `json:"scooterId"`
+	Status    Status    `json:"status"`
+	Stale     bool      `json:"stale,omitempty"`
+	Trip      *Trip     `json:"trip,omitempty"`
+	Replayed  bool      `json:"-"`
+}
+
+const (
+	// DefaultEventLimit is the FindEvents result size when no limit is given.
+	DefaultEventLimit = 100
+	// MaxEventLimit caps the FindEvents result size.
+	MaxEventLimit = 1000
+)
+
+// EventQuery selects the recorded events of a scooter, stale ones included.
+// Results are in the order the events occurred, oldest first, and hold at
+// most Limit events: the earliest ones, or the latest ones if Latest is set.
+type EventQuery struct {
+	ScooterID uuid.UUID
+	Type      EventType
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Latest    bool
+}
+
+// Match reports whether the event satisfies the query filters. Zero-valued
+// filters are ignored; From is inclusive and To exclusive on the time the
+// event occurred.
+func (q EventQuery) Match(e Event) bool {
+	if e.ScooterID != q.ScooterID {
+		return false
+	}
+
+	if q.Type != "" && e.Type != q.Type {
+		return false
+	}
+
+	if !q.From.IsZero() && e.OccurredAt.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && !e.OccurredAt.Before(q.To) {
+		return false
+	}
+
+	return true
 }
 
 type Area struct {
@@ -87,7 +442,39 @@ type Area struct {
 	MaxLng float64 `json:"maxLng"`
 }
 
+// Contains reports whether p lies inside the area, borders included.
+func (a Area) Contains(p Point) bool {
+	return p.Lat >= a.MinLat && p.Lat <= a.MaxLat &&
+		p.Lng >= a.MinLng && p.Lng <= a.MaxLng
+}
+
+// StatusChange is an operator request to move a scooter to another status.
+type StatusChange struct {
+	ScooterID uuid.UUID `json:"-"`
+	Status    Status    `json:"status"`
+}
+
+const (
+	// DefaultPageSize is the FindScooters page size when no limit is given.
+	DefaultPageSize = 100
+	// MaxPageSize caps the FindScooters page size whatever the client asks.
+	MaxPageSize = 500
+)
+
+// Query filters scooters in an area. Results are ordered by ID; After is the
+// last ID of the previous page and Limit the page size (0 means the server
+// maximum, MaxPageSize).
 type Query struct {
-	Area   Area
-	Status Status
+	Area       Area
+	Status     Status
+	MinBattery int
+	Limit      int
+	After      uuid.UUID
+}
+
+// ScooterPage is a page of FindScooters results. Next is the cursor of the
+// following page, empty on the last one.
+type ScooterPage struct {
+	Scooters []Scooter `json:"scooters"`
+	Next     string    `json:"next,omitempty"`
 }
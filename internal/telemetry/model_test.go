@@ -1,6 +1,8 @@
 package telemetry_test
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -50,6 +52,114 @@ func TestScooterStopRide(t *testing.T) {
 	}
 }
 
+func TestScooterApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     telemetry.Status
+		event      telemetry.Event
+		wantStatus telemetry.Status
+		wantErr    bool
+	}{
+		{
+			name:       "free accepts trip start",
+			status:     telemetry.StatusFree,
+			event:      telemetry.Event{Type: telemetry.EventTripStart},
+			wantStatus: telemetry.StatusOccupied,
+		},
+		{
+			name:       "occupied accepts location",
+			status:     telemetry.StatusOccupied,
+			event:      telemetry.Event{Type: telemetry.EventLocation, Lat: 1, Lng: 2},
+			wantStatus: telemetry.StatusOccupied,
+		},
+		{
+			name:       "occupied accepts trip end",
+			status:     telemetry.StatusOccupied,
+			event:      telemetry.Event{Type: telemetry.EventTripEnd},
+			wantStatus: telemetry.StatusFree,
+		},
+		{
+			name:       "occupied rejects trip start",
+			status:     telemetry.StatusOccupied,
+			event:      telemetry.Event{Type: telemetry.EventTripStart},
+			wantStatus: telemetry.StatusOccupied,
+			wantErr:    true,
+		},
+		{
+			name:       "free rejects location",
+			status:     telemetry.StatusFree,
+			event:      telemetry.Event{Type: telemetry.EventLocation, Lat: 1, Lng: 2},
+			wantStatus: telemetry.StatusFree,
+			wantErr:    true,
+		},
+		{
+			name:       "free rejects trip end",
+			status:     telemetry.StatusFree,
+			event:      telemetry.Event{Type: telemetry.EventTripEnd},
+			wantStatus: telemetry.StatusFree,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &telemetry.Scooter{ID: uuid.New(), Status: tt.status}
+
+			err := s.Apply(tt.event)
+			if tt.wantErr != errors.Is(err, telemetry.ErrInvalidTransition) {
+				t.Fatalf("expected invalid transition: %v, got: %v", tt.wantErr, err)
+			}
+
+			if s.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, s.Status)
+			}
+
+			if tt.wantErr && (s.Lat != 0 || s.Lng != 0) {
+				t.Errorf("expected location untouched on rejected event, got (%v, %v)", s.Lat, s.Lng)
+			}
+		})
+	}
+}
+
+func TestScooterIsStale(t *testing.T) {
+	now := time.Now()
+	battery := 50
+
+	s := &telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree}
+	if s.IsStale(telemetry.Event{Type: telemetry.EventLocation, OccurredAt: now.Add(-time.Hour)}) {
+		t.Fatalf("expected no stale events before the first report")
+	}
+
+	err := s.Apply(telemetry.Event{Type: telemetry.EventBattery, Battery: &battery, OccurredAt: now})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if s.LastEventAt == nil || !s.LastEventAt.Equal(now) {
+		t.Fatalf("expected last event at %v, got %v", now, s.LastEventAt)
+	}
+
+	tests := []struct {
+		name  string
+		event telemetry.Event
+		want  bool
+	}{
+		{"older location", telemetry.Event{Type: telemetry.EventLocation, OccurredAt: now.Add(-time.Second)}, true},
+		{"older battery", telemetry.Event{Type: telemetry.EventBattery, OccurredAt: now.Add(-time.Second)}, true},
+		{"same time", telemetry.Event{Type: telemetry.EventLocation, OccurredAt: now}, false},
+		{"newer location", telemetry.Event{Type: telemetry.EventLocation, OccurredAt: now.Add(time.Second)}, false},
+		{"older trip start", telemetry.Event{Type: telemetry.EventTripStart, OccurredAt: now.Add(-time.Second)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.IsStale(tt.event); got != tt.want {
+				t.Errorf("IsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestScooterGenID(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -78,3 +188,33 @@ func TestScooterGenID(t *testing.T) {
 		})
 	}
 }
+
+func TestEventUnmarshalTimestamp(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := at.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		body string
+		want time.Time
+	}{
+		{"occurredAt", `{"type":"location","occurredAt":"2024-05-01T10:00:00Z"}`, at},
+		{"deprecated timestamp", `{"type":"location","timestamp":"2024-05-01T10:00:00Z"}`, at},
+		{"occurredAt wins", `{"type":"location","occurredAt":"2024-05-01T10:01:00Z","timestamp":"2024-05-01T10:00:00Z"}`, later},
+		{"neither", `{"type":"location"}`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e telemetry.Event
+			err := json.Unmarshal([]byte(tt.body), &e)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			if !e.OccurredAt.Equal(tt.want) || e.Type != telemetry.EventLocation {
+				t.Errorf("got %+v, want occurredAt %v", e, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeGeoJSON = "application/geo+json"
+	mediaTypeGPX     = "application/gpx+xml"
+)
+
+// negotiate picks the offered media type the request Accept header prefers.
+// Offers are listed by server preference; the first one is returned when the
+// header is missing or accepts none of them, so clients that do not ask get
+// the default representation.
+func negotiate(r *http.Request, offers ...string) string {
+	best, bestQ := offers[0], 0.0
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		for _, offer := range offers {
+			if q > bestQ && acceptsMediaType(mediaType, offer) {
+				best, bestQ = offer, q
+			}
+		}
+	}
+
+	return best
+}
+
+// acceptsMediaType reports whether an Accept range such as */* or
+// application/* covers the media type.
+func acceptsMediaType(accepted, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(accepted, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
@@ -0,0 +1,287 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// openAPIYAML is the OpenAPI 3 description of the HTTP API. Handlers and the
+// spec are kept in step by the validation middlewares and their tests.
+//
+//go:embed openapi.yaml
+var openAPIYAML []byte
+
+var (
+	ErrUnsupportedMediaType = newError(KindUnsupported, "unsupported_media_type", "unsupported media type")
+	ErrInvalidResponse      = newError(KindInternal, "invalid_response", "response does not match the API spec")
+)
+
+// specIDErrors are the errors of invalid path IDs, by the first tag of the
+// operation, so that they keep the codes the handlers report.
+var specIDErrors = map[string]*Error{
+	"scooters": ErrInvalidID,
+	"trips":    ErrInvalidTripID,
+	"zones":    ErrInvalidZoneID,
+}
+
+// specBodyErrors are the errors of invalid request bodies, by operation ID.
+// Other operations report ErrInvalidBody.
+var specBodyErrors = map[string]*Error{
+	"reportEvent": ErrInvalidEvent,
+}
+
+// specStreamedBodies are the operations, by ID, whose request bodies are read
+// as a stream by the handler. Validating them would buffer the whole body, so
+// their handlers check the media type and contents instead.
+var specStreamedBodies = map[string]bool{
+	"importScooters": true,
+	"reportEvents":   true,
+}
+
+// apiSpec is the OpenAPI document, as served, and the router that finds the
+// operation of a request.
+type apiSpec struct {
+	json   []byte
+	router routers.Router
+}
+
+var loadSpec = sync.OnceValues(func() (*apiSpec, error) {
+	openapi3filter.RegisterBodyDecoder(mediaTypeGeoJSON, openapi3filter.JSONBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(mediaTypeGPX, openapi3filter.PlainBodyDecoder)
+
+	doc, err := openapi3.NewLoader().LoadFromData(openAPIYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	err = doc.Validate(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiSpec{json: b, router: router}, nil
+})
+
+// mustLoadSpec returns the embedded spec. It is part of the binary, so a
+// spec that does not load is a programming error.
+func mustLoadSpec() *apiSpec {
+	spec, err := loadSpec()
+	if err != nil {
+		panic(fmt.Sprintf("openapi: invalid embedded spec: %v", err))
+	}
+
+	return spec
+}
+
+// OpenAPIHandler serves the OpenAPI document as JSON.
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	spec := mustLoadSpec()
+
+	w.Header().Set("Content-Type", mediaTypeJSON)
+	_, _ = w.Write(spec.json)
+}
+
+// RequestValidationMiddleware rejects the requests that do not match the
+// OpenAPI spec. Authentication is left to AuthMiddleware and requests for
+// paths the spec does not describe are passed through. Streamed request bodies
+// are not checked.
+func RequestValidationMiddleware(next http.Handler) http.Handler {
+	spec := mustLoadSpec()
+	opts := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	streamOpts := *opts
+	streamOpts.ExcludeRequestBody = true
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params, err := spec.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{Request: r, PathParams: params, Route: route, Options: opts}
+		if specStreamedBodies[route.Operation.OperationID] {
+			// The security check reads the whole body too, so it is given a
+			// copy of the request without one.
+			input.Request = r.WithContext(r.Context())
+			input.Request.Body = http.NoBody
+			input.Options = &streamOpts
+		}
+		err = openapi3filter.ValidateRequest(r.Context(), input)
+		if err != nil {
+			writeProblem(w, r, specRequestError(route, err))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ResponseValidationMiddleware checks the responses against the OpenAPI spec
+// and replaces those that do not match with a 500 invalid_response problem.
+// Responses are buffered, so it is meant for development and tests; streams
+// are not checked.
+func ResponseValidationMiddleware(next http.Handler) http.Handler {
+	spec := mustLoadSpec()
+	opts := &openapi3filter.Options{IncludeResponseStatus: true}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params, err := spec.router.FindRoute(r)
+		if err != nil || isStreamOperation(route.Operation) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{Request: r, PathParams: params, Route: route},
+			Status:                 buf.status,
+			Header:                 buf.header,
+			Body:                   io.NopCloser(bytes.NewReader(buf.body.Bytes())),
+			Options:                opts,
+		}
+
+		err = openapi3filter.ValidateResponse(r.Context(), input)
+		if err != nil {
+			log.Printf("openapi: %s %s: %v", r.Method, r.URL.Path, err)
+			writeProblem(w, r, fmt.Errorf("%w: %v", ErrInvalidResponse, err))
+			return
+		}
+
+		for k, v := range buf.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(buf.status)
+		_, _ = w.Write(buf.body.Bytes())
+	})
+}
+
+// isStreamOperation reports whether op answers with a stream, an event stream
+// or a protocol switch, that cannot be buffered.
+func isStreamOperation(op *openapi3.Operation) bool {
+	if op.Responses.Status(http.StatusSwitchingProtocols) != nil {
+		return true
+	}
+
+	ok := op.Responses.Status(http.StatusOK)
+	return ok != nil && ok.Value.Content.Get("text/event-stream") != nil
+}
+
+// specRequestError turns a request validation error into the domain error the
+// handler of the route would have reported for the same input.
+func specRequestError(route *routers.Route, err error) error {
+	var re *openapi3filter.RequestError
+	if !errors.As(err, &re) {
+		return fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+
+	op := route.Operation
+	reason := specReason(re)
+
+	if p := re.Parameter; p != nil {
+		sentinel := ErrInvalidQuery
+		if e, ok := specIDErrors[firstTag(op)]; ok && p.In == openapi3.ParameterInPath {
+			sentinel = e
+		}
+
+		if p.Name == "Idempotency-Key" {
+			sentinel = ErrInvalidIdempotencyKey
+		}
+
+		return invalidField(sentinel, p.Name, reason)
+	}
+
+	// A body without error cause was sent with a media type the operation
+	// does not accept.
+	if re.Err == nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, reason)
+	}
+
+	sentinel := ErrInvalidBody
+	if e, ok := specBodyErrors[op.OperationID]; ok {
+		sentinel = e
+	}
+
+	var se *openapi3.SchemaError
+	if errors.As(re.Err, &se) {
+		if ptr := se.JSONPointer(); len(ptr) > 0 {
+			return invalidField(sentinel, strings.Join(ptr, "."), reason)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", sentinel, reason)
+}
+
+func firstTag(op *openapi3.Operation) string {
+	if len(op.Tags) == 0 {
+		return ""
+	}
+
+	return op.Tags[0]
+}
+
+func specReason(re *openapi3filter.RequestError) string {
+	var se *openapi3.SchemaError
+	if errors.As(re.Err, &se) {
+		return se.Reason
+	}
+
+	if re.Err != nil {
+		return re.Err.Error()
+	}
+
+	return re.Reason
+}
+
+// bufferedResponse is a ResponseWriter that keeps the response in memory.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	wrote  bool
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wrote {
+		b.status = status
+		b.wrote = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wrote = true
+	return b.body.Write(p)
+}
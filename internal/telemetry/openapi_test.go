@@ -0,0 +1,273 @@
+package telemetry_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/repo/mem"
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+// specClient sends requests to the router wrapped in the response validation
+// middleware, so that any response drifting from the spec fails as a 500.
+type specClient struct {
+	t       *testing.T
+	handler http.Handler
+}
+
+func newSpecClient(t *testing.T) *specClient {
+	svc := telemetry.NewService(mem.NewTelemetryRepo())
+	router := telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}, "operator")
+	return &specClient{t: t, handler: telemetry.ResponseValidationMiddleware(router)}
+}
+
+func (c *specClient) do(method, path, key string, header http.Header, body string, want int) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	if body != "" && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		r.Header.Set("X-API-Key", key)
+		r.Header.Set("X-Client-ID", "rider-1")
+	}
+
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+
+	if w.Code != want {
+		c.t.Fatalf("%s %s = %d, want %d: %s", method, path, w.Code, want, w.Body.String())
+	}
+
+	return w
+}
+
+func (c *specClient) id(w *httptest.ResponseRecorder, field string) string {
+	c.t.Helper()
+
+	var v map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		c.t.Fatalf("cannot decode %s: %v", w.Body.String(), err)
+	}
+
+	if field == "trip.id" {
+		v, _ = v["trip"].(map[string]any)
+		field = "id"
+	}
+
+	id, _ := v[field].(string)
+	return id
+}
+
+// TestHandlersMatchSpec goes through every operation of the API and fails
+// when a handler answers something the spec does not describe.
+func TestHandlersMatchSpec(t *testing.T) {
+	c := newSpecClient(t)
+	geo := http.Header{"Accept": {"application/geo+json"}}
+
+	c.do("GET", "/api/v1/openapi.json", "", nil, "", http.StatusOK)
+
+	created := c.do("POST", "/api/v1/scooters", "operator", nil, `{"status":"free","lat":45.42,"lng":-75.69,"battery":80}`, http.StatusCreated)
+	scooter := "/api/v1/scooters/" + c.id(created, "id")
+
+	c.do("GET", scooter, "key", nil, "", http.StatusOK)
+	c.do("GET", scooter, "key", http.Header{"If-None-Match": {`"1"`}}, "", http.StatusNotModified)
+	c.do("GET", "/api/v1/scooters/"+uuid.NewString(), "key", nil, "", http.StatusNotFound)
+	c.do("PATCH", scooter, "operator", http.Header{"If-Match": {`"1"`}}, `{"battery":90}`, http.StatusOK)
+	c.do("PATCH", scooter, "operator", http.Header{"If-Match": {`"1"`}}, `{"battery":95}`, http.StatusPreconditionFailed)
+
+	area := "minLat=45.3&minLng=-75.8&maxLat=45.5&maxLng=-75.6"
+	c.do("GET", "/api/v1/scooters?"+area, "key", nil, "", http.StatusOK)
+	c.do("GET", "/api/v1/scooters?"+area, "key", geo, "", http.StatusOK)
+	c.do("GET", "/api/v1/scooters/nearby?lat=45.42&lng=-75.69", "key", nil, "", http.StatusOK)
+	c.do("GET", "/api/v1/scooters/grid?"+area+"&cellSize=0.05", "key", nil, "", http.StatusOK)
+
+	c.do("POST", "/api/v1/scooters/import", "operator", http.Header{"Content-Type": {"text/csv"}}, "lat,lng\n45.41,-75.68\n", http.StatusOK)
+	c.do("GET", "/api/v1/scooters/export", "operator", nil, "", http.StatusOK)
+	c.do("GET", "/api/v1/scooters/export?format=geojson", "operator", nil, "", http.StatusOK)
+
+	zone := `{"name":"Market","rule":"slow_zone","maxSpeed":10,"active":true,"polygon":[{"lat":45.40,"lng":-75.71},{"lat":45.40,"lng":-75.68},{"lat":45.44,"lng":-75.68},{"lat":45.44,"lng":-75.71}]}`
+	zoneCreated := c.do("POST", "/api/v1/zones", "operator", nil, zone, http.StatusCreated)
+	zonePath := "/api/v1/zones/" + c.id(zoneCreated, "id")
+	c.do("GET", "/api/v1/zones", "key", nil, "", http.StatusOK)
+	c.do("GET", zonePath, "key", nil, "", http.StatusOK)
+	c.do("PUT", zonePath, "operator", nil, zone, http.StatusOK)
+
+	id := c.id(created, "id")
+	c.do("POST", "/api/v1/events", "key", nil, `{"scooterId":"`+id+`","type":"trip_start","lat":45.42,"lng":-75.69}`, http.StatusCreated)
+	c.do("POST", "/api/v1/events", "key", http.Header{"Idempotency-Key": {"move-1"}}, `{"scooterId":"`+id+`","type":"location","lat":45.43,"lng":-75.70}`, http.StatusCreated)
+	c.do("POST", "/api/v1/events", "key", nil, `{"scooterId":"`+id+`","type":"battery","battery":75,"timestamp":"`+time.Now().UTC().Format(time.RFC3339)+`"}`, http.StatusCreated)
+	c.do("POST", "/api/v1/events:batch", "key", nil, `[{"scooterId":"`+id+`","type":"battery","battery":70},{"type":"battery"}]`, http.StatusOK)
+	ended := c.do("POST", "/api/v1/events", "key", nil, `{"scooterId":"`+id+`","type":"trip_end","lat":45.43,"lng":-75.70}`, http.StatusCreated)
+	trip := "/api/v1/trips/" + c.id(ended, "trip.id")
+
+	c.do("GET", "/api/v1/trips?scooterId="+id, "key", nil, "", http.StatusOK)
+	c.do("GET", trip, "key", nil, "", http.StatusOK)
+	c.do("GET", trip+"/violations", "key", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/violations", "operator", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/events?type=location&limit=5", "operator", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/events", "key", nil, "", http.StatusForbidden)
+	c.do("GET", scooter+"/track", "operator", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/track?format=gpx", "operator", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/track?format=polyline&tolerance=5", "operator", nil, "", http.StatusOK)
+	c.do("GET", scooter+"/track", "key", nil, "", http.StatusForbidden)
+
+	c.do("PUT", scooter+"/status", "operator", nil, `{"status":"maintenance"}`, http.StatusOK)
+	c.do("PUT", scooter+"/status", "operator", nil, `{"status":"free"}`, http.StatusOK)
+	c.do("POST", scooter+"/reservations", "key", nil, "", http.StatusCreated)
+	c.do("POST", scooter+"/reservations", "key", nil, "", http.StatusConflict)
+
+	c.do("DELETE", zonePath, "operator", nil, "", http.StatusNoContent)
+	spare := c.do("POST", "/api/v1/scooters", "operator", nil, `{"status":"offline","lat":45.42,"lng":-75.69}`, http.StatusCreated)
+	c.do("DELETE", "/api/v1/scooters/"+c.id(spare, "id"), "operator", nil, "", http.StatusNoContent)
+}
+
+// TestSpecCoversRoutes checks that every operation of the spec is routed.
+func TestSpecCoversRoutes(t *testing.T) {
+	svc := telemetry.NewService(mem.NewTelemetryRepo())
+	router := telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}, "operator")
+
+	r := httptest.NewRequest("GET", "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("expected the OpenAPI document to be served without an API key, got %d %v", w.Code, err)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+
+			r := httptest.NewRequest(strings.ToUpper(method), strings.ReplaceAll(path, "{id}", uuid.NewString()), nil)
+			r.Header.Set("X-API-Key", "operator")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			body, _ := io.ReadAll(w.Body)
+			if w.Code == http.StatusMethodNotAllowed || strings.Contains(string(body), "404 page not found") {
+				t.Errorf("%s %s is in the spec but not routed", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	scooter := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooter)))
+	router := telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}, "operator")
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		code        string
+		field       string
+	}{
+		{"path ID", "GET", "/api/v1/scooters/nope", "", "", http.StatusBadRequest, "invalid_scooter_id", "id"},
+		{"zone ID", "GET", "/api/v1/zones/nope", "", "", http.StatusBadRequest, "invalid_zone_id", "id"},
+		{"query type", "GET", "/api/v1/scooters?minLat=a&minLng=-75.8&maxLat=45.5&maxLng=-75.6", "", "", http.StatusBadRequest, "invalid_query", "minLat"},
+		{"missing query", "GET", "/api/v1/scooters/nearby?lat=45.4", "", "", http.StatusBadRequest, "invalid_query", "lng"},
+		{"non-finite query", "GET", "/api/v1/scooters/nearby?lat=45.4&lng=NaN", "", "", http.StatusBadRequest, "invalid_query", "lng"},
+		{"grid NaN", "GET", "/api/v1/scooters/grid?minLat=NaN&minLng=-75.8&maxLat=45.5&maxLng=-75.6", "", "", http.StatusBadRequest, "invalid_query", "minLat"},
+		{"query range", "GET", "/api/v1/scooters/nearby?lat=45.4&lng=-75.6&limit=1000", "", "", http.StatusBadRequest, "invalid_query", "limit"},
+		{"event field", "POST", "/api/v1/events", "application/json", `{"scooterId":"` + scooter.ID.String() + `","type":"battery","battery":"full"}`, http.StatusBadRequest, "invalid_event", "battery"},
+		{"event type", "POST", "/api/v1/events", "application/json", `{"scooterId":"` + scooter.ID.String() + `","type":"jump"}`, http.StatusBadRequest, "invalid_event", "type"},
+		{"media type", "POST", "/api/v1/zones", "text/plain", `{}`, http.StatusUnsupportedMediaType, "unsupported_media_type", ""},
+		{"import format", "POST", "/api/v1/scooters/import", "application/xml", `<fleet/>`, http.StatusUnsupportedMediaType, "unsupported_format", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("X-API-Key", "operator")
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			var p telemetry.Problem
+			_ = json.Unmarshal(w.Body.Bytes(), &p)
+
+			if w.Code != tt.status || p.Code != tt.code {
+				t.Fatalf("expected %d %s, got %d %s", tt.status, tt.code, w.Code, w.Body.String())
+			}
+
+			if tt.field != "" && (len(p.Errors) == 0 || p.Errors[0].Field != tt.field) {
+				t.Errorf("expected the error on %q, got %+v", tt.field, p.Errors)
+			}
+		})
+	}
+}
+
+func TestRequestValidationStreams(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+	}{
+		{"import", "/api/v1/scooters/import", "text/csv"},
+		{"event batch", "/api/v1/events:batch", "application/x-ndjson"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The body is only closed once the handler read its first line, so
+			// a middleware buffering it would never call the handler.
+			pr, pw := io.Pipe()
+			read := make(chan struct{})
+			go func() {
+				_, _ = io.WriteString(pw, "first line\n")
+				<-read
+				_ = pw.Close()
+			}()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				line, _ := bufio.NewReader(r.Body).ReadString('\n')
+				if line != "first line\n" {
+					t.Errorf("expected the first line, got %q", line)
+				}
+				close(read)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, tt.path, pr)
+			r.Header.Set("Content-Type", tt.contentType)
+
+			done := make(chan struct{})
+			go func() {
+				telemetry.RequestValidationMiddleware(next).ServeHTTP(httptest.NewRecorder(), r)
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				_ = pw.CloseWithError(errors.New("timeout"))
+				t.Fatal("expected the body to be streamed to the handler")
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const mediaTypeProblem = "application/problem+json"
+
+// Problem is an RFC 9457 problem details body. Code is the stable error code
+// clients branch on; Detail is informative only.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// kindStatus maps every error kind to its HTTP status.
+var kindStatus = map[ErrorKind]int{
+	KindInternal:     http.StatusInternalServerError,
+	KindInvalid:      http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindPrecondition: http.StatusPreconditionFailed,
+	KindUnsupported:  http.StatusUnsupportedMediaType,
+}
+
+// errStatus maps an error to its HTTP status code.
+func errStatus(err error) int {
+	return kindStatus[KindOf(err)]
+}
+
+// NewProblem describes err as a problem occurred while serving r.
+func NewProblem(r *http.Request, err error) Problem {
+	status := errStatus(err)
+	requestID, _ := RequestID(r.Context())
+
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Code:      ErrorCode(err),
+		Detail:    ErrorMessage(err),
+		Instance:  r.URL.Path,
+		RequestID: requestID,
+		Errors:    FieldErrors(err),
+	}
+}
+
+// writeProblem writes err as an application/problem+json response.
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(r, err)
+
+	w.Header().Set("Content-Type", mediaTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
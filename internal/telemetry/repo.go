@@ -2,13 +2,90 @@ package telemetry
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrNotFound is returned by Repo implementations when the requested entity
+	// does not exist.
+	ErrNotFound = newError(KindNotFound, "not_found", "not found")
+	// ErrAlreadyExists is returned by Repo implementations when creating an
+	// entity whose ID is already taken.
+	ErrAlreadyExists = newError(KindConflict, "already_exists", "already exists")
+	// ErrVersionConflict is returned when updating an entity that has been
+	// changed since it was read.
+	ErrVersionConflict = newError(KindPrecondition, "version_conflict", "version conflict")
+)
+
 type Repo interface {
+	// InTx runs fn in a transaction carried by the context fn is given: the
+	// repo calls made with it are committed together if fn returns nil and
+	// rolled back otherwise.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// GetScooter returns the scooter or ErrNotFound.
 	GetScooter(ctx context.Context, id uuid.UUID) (Scooter, error)
+	// LockScooter is GetScooter that also locks the scooter until the
+	// transaction of ctx ends, so that the instances sharing the store change
+	// it one at a time.
+	LockScooter(ctx context.Context, id uuid.UUID) (Scooter, error)
+	CreateScooter(ctx context.Context, s Scooter) error
+	// UpdateScooter stores s if the stored scooter is still at s.Version and
+	// bumps the version, otherwise it returns ErrVersionConflict, or
+	// ErrNotFound when there is no such scooter.
 	UpdateScooter(ctx context.Context, s Scooter) error
-	FindScootersInArea(ctx context.Context, area Area, status Status) ([]Scooter, error)
+	// DeleteScooter soft deletes the scooter by marking it decommissioned.
+	DeleteScooter(ctx context.Context, id uuid.UUID) error
+	// FindScootersInArea leaves decommissioned scooters out unless the query
+	// asks for that status.
+	FindScootersInArea(ctx context.Context, qry Query) ([]Scooter, error)
+	// FindNearbyScooters returns up to qry.Limit scooters within qry.Radius
+	// of qry.Center, nearest first.
+	FindNearbyScooters(ctx context.Context, qry NearbyQuery) ([]NearbyScooter, error)
+	// CountScootersByCell counts the scooters in qry.Area by grid cell and
+	// status, area borders included. Decommissioned scooters are left out
+	// unless the query asks for that status.
+	CountScootersByCell(ctx context.Context, qry GridQuery) ([]CellCount, error)
+	// ListScooters returns every stored scooter, decommissioned ones included,
+	// ordered by ID.
+	ListScooters(ctx context.Context) ([]Scooter, error)
+	// StoreEvent returns ErrAlreadyExists if an event with the same ID is
+	// already stored.
 	StoreEvent(ctx context.Context, e Event) error
+	// FindEvents returns the events matching the query in the order they
+	// occurred, the earliest or latest qry.Limit of them.
+	FindEvents(ctx context.Context, qry EventQuery) ([]Event, error)
+	// GetProcessedEvent returns the event processed under the client key since
+	// the given time or ErrNotFound.
+	GetProcessedEvent(ctx context.Context, clientID, key string, since time.Time) (ProcessedEvent, error)
+	// SaveProcessedEvent stores p, replacing any older record under the same
+	// client key.
+	SaveProcessedEvent(ctx context.Context, p ProcessedEvent) error
+	// DeleteProcessedEvents deletes the processed events created before the
+	// given time and returns how many were deleted.
+	DeleteProcessedEvents(ctx context.Context, before time.Time) (int, error)
+
+	CreateTrip(ctx context.Context, t Trip) error
+	UpdateTrip(ctx context.Context, t Trip) error
+	GetTrip(ctx context.Context, id uuid.UUID) (Trip, error)
+	// GetActiveTrip returns the open trip for the scooter or ErrNotFound.
+	GetActiveTrip(ctx context.Context, scooterID uuid.UUID) (Trip, error)
+	FindTrips(ctx context.Context, qry TripQuery) ([]Trip, error)
+
+	CreateReservation(ctx context.Context, r Reservation) error
+	// GetReservation returns the reservation held on the scooter or ErrNotFound.
+	GetReservation(ctx context.Context, scooterID uuid.UUID) (Reservation, error)
+	DeleteReservation(ctx context.Context, scooterID uuid.UUID) error
+	FindExpiredReservations(ctx context.Context, at time.Time) ([]Reservation, error)
+
+	CreateZone(ctx context.Context, z Zone) error
+	UpdateZone(ctx context.Context, z Zone) error
+	GetZone(ctx context.Context, id uuid.UUID) (Zone, error)
+	DeleteZone(ctx context.Context, id uuid.UUID) error
+	ListZones(ctx context.Context) ([]Zone, error)
+	// FindZonesContaining returns the active zones whose polygon contains p.
+	FindZonesContaining(ctx context.Context, p Point) ([]Zone, error)
+	CreateViolation(ctx context.Context, v Violation) error
+	FindViolations(ctx context.Context, qry ViolationQuery) ([]Violation, error)
 }
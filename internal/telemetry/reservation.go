@@ -0,0 +1,44 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultReservationTTL is how long a rider can hold a free scooter.
+	DefaultReservationTTL = 5 * time.Minute
+	// ReservationSweepInterval is how often expired reservations are released.
+	ReservationSweepInterval = 10 * time.Second
+)
+
+var ErrReservedByOther = newError(KindConflict, "reserved_by_other", "scooter reserved by another client")
+
+// Reservation holds a free scooter for a client until it expires or the client
+// starts a ride on it.
+type Reservation struct {
+	ID        uuid.UUID `json:"id"`
+	ScooterID uuid.UUID `json:"scooterId"`
+	ClientID  string    `json:"clientId"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (r *Reservation) GenID() {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+}
+
+// GenCreateVals sets the ID and the reservation window starting now.
+func (r *Reservation) GenCreateVals(ttl time.Duration) {
+	r.GenID()
+	r.CreatedAt = time.Now()
+	r.ExpiresAt = r.CreatedAt.Add(ttl)
+}
+
+// Expired reports whether the reservation is no longer binding at t.
+func (r *Reservation) Expired(t time.Time) bool {
+	return !t.Before(r.ExpiresAt)
+}
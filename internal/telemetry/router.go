@@ -2,14 +2,39 @@ package telemetry
 
 import "net/http"
 
-func NewRouter(handler *Handler, apiKeys ...string) *http.ServeMux {
+func NewRouter(handler *Handler, apiKeys []string, operatorAPIKeys ...string) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	apiMux := http.NewServeMux()
 	apiMux.HandleFunc("GET /api/v1/scooters", handler.FindScooters)
+	apiMux.HandleFunc("GET /api/v1/scooters/nearby", handler.FindNearbyScooters)
+	apiMux.HandleFunc("GET /api/v1/scooters/grid", handler.ScooterGrid)
+	apiMux.HandleFunc("GET /api/v1/scooters/stream", handler.StreamScooters)
+	apiMux.HandleFunc("POST /api/v1/scooters", handler.CreateScooter)
+	apiMux.HandleFunc("GET /api/v1/scooters/{id}", handler.GetScooter)
+	apiMux.HandleFunc("PATCH /api/v1/scooters/{id}", handler.UpdateScooter)
+	apiMux.HandleFunc("DELETE /api/v1/scooters/{id}", handler.DeleteScooter)
+	apiMux.HandleFunc("POST /api/v1/scooters/import", handler.ImportScooters)
+	apiMux.HandleFunc("GET /api/v1/scooters/export", handler.ExportScooters)
+	apiMux.HandleFunc("PUT /api/v1/scooters/{id}/status", handler.ChangeStatus)
+	apiMux.HandleFunc("POST /api/v1/scooters/{id}/reservations", handler.ReserveScooter)
+	apiMux.HandleFunc("GET /api/v1/scooters/{id}/violations", handler.FindScooterViolations)
+	apiMux.HandleFunc("GET /api/v1/scooters/{id}/events", handler.FindScooterEvents)
+	apiMux.HandleFunc("GET /api/v1/scooters/{id}/track", handler.ScooterTrack)
+	apiMux.HandleFunc("GET /api/v1/scooters/{id}/channel", handler.ConnectDevice)
 	apiMux.HandleFunc("POST /api/v1/events", handler.ReportEvent)
+	apiMux.HandleFunc("POST /api/v1/events:batch", handler.ReportEvents)
+	apiMux.HandleFunc("GET /api/v1/trips", handler.FindTrips)
+	apiMux.HandleFunc("GET /api/v1/trips/{id}", handler.GetTrip)
+	apiMux.HandleFunc("GET /api/v1/trips/{id}/violations", handler.FindTripViolations)
+	apiMux.HandleFunc("GET /api/v1/zones", handler.ListZones)
+	apiMux.HandleFunc("POST /api/v1/zones", handler.CreateZone)
+	apiMux.HandleFunc("GET /api/v1/zones/{id}", handler.GetZone)
+	apiMux.HandleFunc("PUT /api/v1/zones/{id}", handler.UpdateZone)
+	apiMux.HandleFunc("DELETE /api/v1/zones/{id}", handler.DeleteZone)
 
-	mux.Handle("/api/v1/", AuthMiddleware(apiKeys)(apiMux))
+	mux.Handle("/api/v1/", RequestIDMiddleware(AuthMiddleware(apiKeys, operatorAPIKeys...)(RequestValidationMiddleware(apiMux))))
+	mux.HandleFunc("GET /api/v1/openapi.json", OpenAPIHandler)
 	mux.HandleFunc("GET /healthz", HealthzHandler)
 
 	return mux
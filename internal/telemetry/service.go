@@ -2,29 +2,118 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Service interface {
 	GetScooter(ctx context.Context, id uuid.UUID) (Scooter, error)
-	UpdateScooter(ctx context.Context, s Scooter) error
-	FindScooters(ctx context.Context, qry Query) ([]Scooter, error)
-	ReportEvent(ctx context.Context, e Event) error
+	CreateScooter(ctx context.Context, s Scooter) (Scooter, error)
+	UpdateScooter(ctx context.Context, p ScooterPatch) (Scooter, error)
+	DeleteScooter(ctx context.Context, id uuid.UUID) error
+	ImportScooters(ctx context.Context, format FleetFormat, r io.Reader) (ImportResult, error)
+	ExportScooters(ctx context.Context, format FleetFormat, w io.Writer) error
+	FindScooters(ctx context.Context, qry Query) (ScooterPage, error)
+	FindNearbyScooters(ctx context.Context, qry NearbyQuery) ([]NearbyScooter, error)
+	ScooterGrid(ctx context.Context, qry GridQuery) (DensityGrid, error)
+	ReportEvent(ctx context.Context, e Event) (EventResult, error)
+	ReportEvents(ctx context.Context, events []Event) ([]BatchItem, error)
+	FindEvents(ctx context.Context, qry EventQuery) ([]Event, error)
+	Track(ctx context.Context, qry TrackQuery) (Track, error)
+	WatchScooters(ctx context.Context, qry StreamQuery) (*Subscription, error)
+	PurgeProcessedEvents(ctx context.Context) (int, error)
+	ChangeStatus(ctx context.Context, change StatusChange) (Scooter, error)
+	ReserveScooter(ctx context.Context, id uuid.UUID) (Reservation, error)
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+	GetTrip(ctx context.Context, id uuid.UUID) (Trip, error)
+	FindTrips(ctx context.Context, qry TripQuery) ([]Trip, error)
+	CreateZone(ctx context.Context, z Zone) (Zone, error)
+	UpdateZone(ctx context.Context, z Zone) (Zone, error)
+	GetZone(ctx context.Context, id uuid.UUID) (Zone, error)
+	DeleteZone(ctx context.Context, id uuid.UUID) error
+	ListZones(ctx context.Context) ([]Zone, error)
+	FindViolations(ctx context.Context, qry ViolationQuery) ([]Violation, error)
 }
 
 type service struct {
-	repo     Repo
-	validate Validator
+	repo           Repo
+	validate       Validator
+	locks          scooterLocks
+	lowBattery     int
+	reservationTTL time.Duration
+	dedupWindow    time.Duration
+	maxClockSkew   time.Duration
+	pricer         Pricer
+	feed           *changeFeed
 }
 
-func NewService(r Repo) Service {
-	return &service{
-		repo:     r,
-		validate: DefaultValidator,
+// Option configures optional service behavior.
+type Option func(*service)
+
+// WithLowBatteryThreshold sets the battery percentage below which idle
+// scooters are no longer rentable.
+func WithLowBatteryThreshold(pct int) Option {
+	return func(s *service) {
+		s.lowBattery = pct
+	}
+}
+
+// WithReservationTTL sets how long a reservation holds a scooter.
+func WithReservationTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.reservationTTL = ttl
 	}
 }
 
+// WithDedupWindow sets how long a reported event is remembered so that a
+// retry gets the original result instead of being applied again. A zero
+// window disables deduplication.
+func WithDedupWindow(d time.Duration) Option {
+	return func(s *service) {
+		s.dedupWindow = d
+	}
+}
+
+// WithMaxClockSkew sets how far in the future, relative to the server clock,
+// the device time of an event may be before the event is rejected.
+func WithMaxClockSkew(d time.Duration) Option {
+	return func(s *service) {
+		s.maxClockSkew = d
+	}
+}
+
+// WithPricer sets the pricer used to compute the fare of every ride when it
+// ends. Without one, trips are closed unpriced.
+func WithPricer(p Pricer) Option {
+	return func(s *service) {
+		s.pricer = p
+	}
+}
+
+func NewService(r Repo, opts ...Option) Service {
+	s := &service{
+		repo:           r,
+		validate:       DefaultValidator,
+		lowBattery:     DefaultLowBatteryThreshold,
+		reservationTTL: DefaultReservationTTL,
+		dedupWindow:    DefaultDedupWindow,
+		maxClockSkew:   DefaultMaxClockSkew,
+		feed:           newChangeFeed(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
 func (s *service) GetScooter(ctx context.Context, id uuid.UUID) (Scooter, error) {
 	err := s.validate(OpGetScooter, id)
 	if err != nil {
@@ -34,57 +123,1051 @@ func (s *service) GetScooter(ctx context.Context, id uuid.UUID) (Scooter, error)
 	return s.repo.GetScooter(ctx, id)
 }
 
-func (s *service) UpdateScooter(ctx context.Context, scooter Scooter) error {
-	err := s.validate(OpUpdateScooter, scooter)
+// CreateScooter registers a new scooter in the fleet. It starts free unless
+// another idle status is given, and the ID is generated when not provided.
+func (s *service) CreateScooter(ctx context.Context, scooter Scooter) (Scooter, error) {
+	if !IsOperator(ctx) {
+		return Scooter{}, ErrOperatorOnly
+	}
+
+	if scooter.Status == "" {
+		scooter.Status = StatusFree
+	}
+
+	err := s.validate(OpCreateScooter, scooter)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	scooter.GenCreateVals()
+	scooter.CheckBattery(s.lowBattery)
+
+	err = s.createScooter(ctx, scooter)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	return scooter, nil
+}
+
+// UpdateScooter lets an operator correct the location or battery level of a
+// scooter. Only the fields set in the patch are changed. A patch based on a
+// version other than the current one is rejected with ErrVersionConflict so
+// that concurrent changes are not overwritten.
+func (s *service) UpdateScooter(ctx context.Context, p ScooterPatch) (Scooter, error) {
+	if !IsOperator(ctx) {
+		return Scooter{}, ErrOperatorOnly
+	}
+
+	err := s.validate(OpUpdateScooter, p)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	unlock := s.locks.lock(p.ScooterID)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, p.ScooterID)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	if p.Version != 0 && p.Version != scooter.Version {
+		return Scooter{}, fmt.Errorf("%w: scooter is at version %d", ErrVersionConflict, scooter.Version)
+	}
+
+	if scooter.Status == StatusDecommissioned {
+		return Scooter{}, fmt.Errorf("%w: scooter is %s", ErrInvalidTransition, scooter.Status)
+	}
+
+	scooter.Patch(p)
+	scooter.CheckBattery(s.lowBattery)
+
+	err = s.saveScooter(ctx, &scooter)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	return scooter, nil
+}
+
+// createScooter stores a new scooter and streams it to the watchers.
+func (s *service) createScooter(ctx context.Context, scooter Scooter) error {
+	err := s.repo.CreateScooter(ctx, scooter)
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, scooter)
+	return nil
+}
+
+// saveScooter stores a changed scooter, moves it to the stored version and
+// streams the change to the watchers.
+func (s *service) saveScooter(ctx context.Context, scooter *Scooter) error {
+	err := s.repo.UpdateScooter(ctx, *scooter)
+	if err != nil {
+		return err
+	}
+
+	scooter.Version++
+	s.publish(ctx, *scooter)
+	return nil
+}
+
+// publish streams a stored scooter change to the watchers.
+func (s *service) publish(ctx context.Context, scooter Scooter) {
+	origin, _ := RequestID(ctx)
+	s.feed.publish(origin, scooter)
+}
+
+// DeleteScooter decommissions a scooter. It stays stored for trip and event
+// history but is no longer listed nor rentable. Any reservation on it is
+// dropped; scooters in a ride must be ended first.
+func (s *service) DeleteScooter(ctx context.Context, id uuid.UUID) error {
+	if !IsOperator(ctx) {
+		return ErrOperatorOnly
+	}
+
+	err := s.validate(OpGetScooter, id)
+	if err != nil {
+		return err
+	}
+
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if scooter.Status == StatusDecommissioned {
+		return nil
+	}
+
+	reserved := scooter.Status == StatusReserved
+
+	err = scooter.Decommission()
+	if err != nil {
+		return err
+	}
+
+	if reserved {
+		err = s.repo.DeleteReservation(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.repo.DeleteScooter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	scooter.Version++
+	s.publish(ctx, scooter)
+	return nil
+}
+
+// ImportScooters upserts the scooters read from r. Rows are streamed and
+// applied one at a time; a row that cannot be parsed or applied is reported in
+// the result and the import goes on. Scooters in a ride, reserved or
+// decommissioned are left untouched, and status changes of existing scooters
+// are limited to those an operator can make. A malformed file aborts the
+// import, keeping the rows already applied.
+func (s *service) ImportScooters(ctx context.Context, format FleetFormat, r io.Reader) (ImportResult, error) {
+	res := ImportResult{Errors: []RowError{}}
+
+	if !IsOperator(ctx) {
+		return res, ErrOperatorOnly
+	}
+
+	fr, err := NewFleetReader(format, r)
+	if err != nil {
+		return res, err
+	}
+
+	for {
+		row, err := fr.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			res.fail(row, rowErr.err)
+			continue
+		}
+
+		if err != nil {
+			return res, err
+		}
+
+		created, err := s.importRow(ctx, row)
+		if err != nil {
+			res.fail(row, err)
+			continue
+		}
+
+		if created {
+			res.Created++
+		} else {
+			res.Updated++
+		}
+	}
+}
+
+// importRow creates or updates the scooter described by row and reports
+// whether it was created.
+func (s *service) importRow(ctx context.Context, row FleetRow) (bool, error) {
+	if row.ID == uuid.Nil {
+		row.ID = uuid.New()
+	}
+
+	unlock := s.locks.lock(row.ID)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, row.ID)
+	created := errors.Is(err, ErrNotFound)
+	if err != nil && !created {
+		return false, err
+	}
+
+	switch scooter.Status {
+	case StatusOccupied, StatusReserved, StatusDecommissioned:
+		return false, fmt.Errorf("%w: scooter is %s", ErrInvalidTransition, scooter.Status)
+	}
+
+	if created {
+		if row.Battery == nil {
+			return false, fmt.Errorf("%w: missing battery level", ErrInvalidScooter)
+		}
+		scooter = Scooter{ID: row.ID, Status: StatusFree}
+		scooter.GenCreateVals()
+	}
+
+	switch {
+	case row.Status == "" || row.Status == scooter.Status:
+	case created:
+		scooter.Status = row.Status
+	default:
+		err = scooter.SetStatus(row.Status)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if row.Battery != nil {
+		scooter.Battery = *row.Battery
+	}
+
+	scooter.Lat, scooter.Lng = row.Lat, row.Lng
+
+	err = s.validate(OpImportScooter, scooter)
+	if err != nil {
+		return false, err
+	}
+
+	scooter.AuditUpdate()
+	scooter.CheckBattery(s.lowBattery)
+
+	if created {
+		return true, s.createScooter(ctx, scooter)
+	}
+
+	return false, s.saveScooter(ctx, &scooter)
+}
+
+// ExportScooters writes the whole fleet, decommissioned scooters included, to
+// w in the given format.
+func (s *service) ExportScooters(ctx context.Context, format FleetFormat, w io.Writer) error {
+	if !IsOperator(ctx) {
+		return ErrOperatorOnly
+	}
+
+	scooters, err := s.repo.ListScooters(ctx)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.UpdateScooter(ctx, scooter)
+	return WriteFleet(format, w, scooters)
 }
 
-func (s *service) FindScooters(ctx context.Context, qry Query) ([]Scooter, error) {
+// FindScooters returns a page of the scooters in an area ordered by ID. Page
+// sizes above MaxPageSize, or unset, are capped to it.
+func (s *service) FindScooters(ctx context.Context, qry Query) (ScooterPage, error) {
 	err := s.validate(OpFindScooters, qry)
+	if err != nil {
+		return ScooterPage{}, err
+	}
+
+	if qry.Limit == 0 || qry.Limit > MaxPageSize {
+		qry.Limit = MaxPageSize
+	}
+
+	// Ask for one more to know whether there is a next page.
+	limit := qry.Limit
+	qry.Limit++
+
+	scooters, err := s.repo.FindScootersInArea(ctx, qry)
+	if err != nil {
+		return ScooterPage{}, err
+	}
+
+	page := ScooterPage{Scooters: scooters}
+	if len(scooters) > limit {
+		page.Scooters = scooters[:limit]
+		page.Next = encodeCursor(page.Scooters[limit-1].ID)
+	}
+
+	if page.Scooters == nil {
+		page.Scooters = []Scooter{}
+	}
+
+	return page, nil
+}
+
+// FindNearbyScooters returns the scooters around a point sorted by distance,
+// nearest first.
+func (s *service) FindNearbyScooters(ctx context.Context, qry NearbyQuery) ([]NearbyScooter, error) {
+	err := s.validate(OpFindNearby, qry)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.FindScootersInArea(ctx, qry.Area, qry.Status)
+	return s.repo.FindNearbyScooters(ctx, qry)
+}
+
+// ScooterGrid returns the scooter density over an area, counted by grid cell
+// and status, for heatmaps.
+func (s *service) ScooterGrid(ctx context.Context, qry GridQuery) (DensityGrid, error) {
+	err := s.validate(OpScooterGrid, qry)
+	if err != nil {
+		return DensityGrid{}, err
+	}
+
+	counts, err := s.repo.CountScootersByCell(ctx, qry)
+	if err != nil {
+		return DensityGrid{}, err
+	}
+
+	return NewDensityGrid(qry, counts), nil
 }
 
 // ReportEvent processes an incoming event and updates the scooter state accordingly.
+// Events that are not allowed in the scooter's current status are rejected with
+// ErrInvalidTransition and are not stored. Once a ride is started, only the
+// client that started it may report events for the scooter until the trip is
+// closed. Idle scooters whose battery is below the configured threshold are
+// moved to low_battery and cannot be rented. A reserved scooter can only be
+// started by the client holding the reservation.
+// Location and trip_end events are checked against the active geofences and
+// any rule they break is recorded as a violation. Ending a ride prices the
+// trip and returns it in the result.
+//
+// Events are deduplicated by the idempotency key of the request or, without
+// one, by the client supplied event ID. A retry within the dedup window gets
+// the original result, flagged as replayed, and is not applied again.
+//
+// Events are ordered by their device time. A location or battery report older
+// than the last one applied is stored flagged as stale but leaves the scooter
+// untouched. Events whose device time is ahead of the server clock by more
+// than the tolerated skew are rejected. Trips are timed, and billed, by the
+// server clock: ride events whose device time lags it by more than the
+// tolerated skew get their reception time.
 //
 // NOTE: In a production system, an event streaming approach (e.g., using NATS)
 // could be used for decoupling, scalability, and reliability. For this home assignment,
 // we use a simpler approach: events are processed synchronously and
 // directly update the scooter state if no errors occur. See docs/adr/0001-event-processing-vs-streaming.md.
-func (s *service) ReportEvent(ctx context.Context, e Event) error {
+func (s *service) ReportEvent(ctx context.Context, e Event) (EventResult, error) {
 	if err := s.validate(OpReportEvent, e); err != nil {
-		return err
+		return EventResult{}, err
 	}
 
+	key := s.eventKey(ctx, e)
 	e.GenCreateVals()
 
-	err := s.repo.StoreEvent(ctx, e)
+	err := e.CheckClockSkew(s.maxClockSkew)
 	if err != nil {
+		return EventResult{}, err
+	}
+	e.ClampRideTime(s.maxClockSkew)
+
+	unlock := s.locks.lock(e.ScooterID)
+	defer unlock()
+
+	if key != "" {
+		res, ok, err := s.replay(ctx, key, e)
+		if err != nil || ok {
+			return res, err
+		}
+	}
+
+	// The event, the trip, the violations and the scooter are written
+	// together: a failed write leaves neither an orphan event nor an open trip
+	// behind.
+	var res EventResult
+	var changed *Scooter
+	err = s.repo.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		res, changed, err = s.applyEvent(ctx, e)
 		return err
+	})
+	if err != nil {
+		return EventResult{}, err
 	}
 
-	scooter, err := s.repo.GetScooter(ctx, e.ScooterID)
+	if changed != nil {
+		s.publish(ctx, *changed)
+	}
+
+	if key != "" {
+		s.remember(ctx, key, e, res)
+	}
+
+	return res, nil
+}
+
+// applyEvent stores e and applies it to its scooter, which is locked for the
+// transaction of ctx. It returns the changed scooter, nil for stale reports,
+// to be published once the transaction is committed.
+func (s *service) applyEvent(ctx context.Context, e Event) (EventResult, *Scooter, error) {
+	scooter, err := s.repo.LockScooter(ctx, e.ScooterID)
 	if err != nil {
-		return err
+		return EventResult{}, nil, err
+	}
+
+	trip, err := s.rideTrip(ctx, scooter, e)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+
+	if scooter.IsStale(e) {
+		res, err := s.recordStale(ctx, scooter, e)
+		return res, nil, err
+	}
+
+	reserved := scooter.Status == StatusReserved
+	prev := scooter
+
+	err = scooter.Apply(e)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+
+	scooter.CheckBattery(s.lowBattery)
+
+	err = s.repo.StoreEvent(ctx, e)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+
+	err = s.trackTrip(ctx, scooter, trip, e)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+
+	err = s.checkZones(ctx, prev, scooter, trip, e)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+
+	if reserved && e.Type == EventTripStart {
+		err = s.repo.DeleteReservation(ctx, scooter.ID)
+		if err != nil {
+			return EventResult{}, nil, err
+		}
+	}
+
+	err = s.repo.UpdateScooter(ctx, scooter)
+	if err != nil {
+		return EventResult{}, nil, err
+	}
+	scooter.Version++
+
+	res := EventResult{EventID: e.ID, ScooterID: scooter.ID, Status: scooter.Status}
+	if e.Type == EventTripEnd {
+		res.Trip = trip
+	}
+
+	return res, &scooter, nil
+}
+
+// recordStale stores a report that arrived after a newer one without applying
+// it to the scooter.
+func (s *service) recordStale(ctx context.Context, scooter Scooter, e Event) (EventResult, error) {
+	e.Stale = true
+
+	err := s.repo.StoreEvent(ctx, e)
+	if err != nil {
+		return EventResult{}, err
+	}
+
+	return EventResult{EventID: e.ID, ScooterID: scooter.ID, Status: scooter.Status, Stale: true}, nil
+}
+
+// ReportEvents processes a batch of events, typically buffered by scooters
+// while offline and replayed once back online. Every event goes through
+// ReportEvent; the events of a scooter are applied in batch order while
+// different scooters are processed concurrently. A rejected event does not
+// stop the batch, its error is returned in its item. Items are returned in
+// the order of events.
+func (s *service) ReportEvents(ctx context.Context, events []Event) ([]BatchItem, error) {
+	if len(events) > MaxBatchSize {
+		return nil, fmt.Errorf("%w: more than %d events", ErrInvalidBatch, MaxBatchSize)
+	}
+
+	byScooter := make(map[uuid.UUID][]int)
+	for i, e := range events {
+		byScooter[e.ScooterID] = append(byScooter[e.ScooterID], i)
+	}
+
+	items := make([]BatchItem, len(events))
+
+	var wg sync.WaitGroup
+	for _, indexes := range byScooter {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			for _, i := range indexes {
+				items[i].Result, items[i].Err = s.ReportEvent(ctx, events[i])
+			}
+		}(indexes)
+	}
+
+	wg.Wait()
+
+	return items, nil
+}
+
+// WatchScooters subscribes to the changes of the scooters inside the query
+// area, or of the query scooter, as they are stored, starting after qry.LastEventID when it is still
+// known. The caller must close the subscription.
+func (s *service) WatchScooters(ctx context.Context, qry StreamQuery) (*Subscription, error) {
+	err := s.validate(OpWatchScooters, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.feed.subscribe(qry), nil
+}
+
+// rideTrip returns the open trip an event belongs to, making sure the event,
+// whatever its type, is sent by the same client that started the ride. A trip_start must carry the
+// client ID the ride will be bound to and, on a reserved scooter, match the
+// reservation holder.
+func (s *service) rideTrip(ctx context.Context, scooter Scooter, e Event) (*Trip, error) {
+	clientID, _ := ClientID(ctx)
+
+	if e.Type == EventTripStart {
+		if clientID == "" {
+			return nil, ErrMissingClientID
+		}
+
+		if scooter.Status == StatusReserved {
+			return nil, s.checkReservation(ctx, scooter.ID, clientID)
+		}
+
+		return nil, nil
+	}
+
+	trip, err := s.repo.GetActiveTrip(ctx, e.ScooterID)
+	if errors.Is(err, ErrNotFound) {
+		// The ride started before trips were tracked (e.g. seeded occupied
+		// scooters), there is no owner to check against.
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.ClientID != clientID {
+		return nil, ErrNotRideOwner
+	}
+
+	return &trip, nil
+}
+
+// trackTrip keeps the scooter trip in sync with an event that has just been
+// applied: trip_start opens a trip, location extends it and trip_end closes and
+// prices it.
+func (s *service) trackTrip(ctx context.Context, scooter Scooter, trip *Trip, e Event) error {
+	if e.Type == EventTripStart {
+		clientID, _ := ClientID(ctx)
+		trip := Trip{
+			ScooterID: scooter.ID,
+			ClientID:  clientID,
+			Start:     scooter.Location(),
+		}
+		trip.GenCreateVals(e.ReceivedAt)
+
+		return s.repo.CreateTrip(ctx, trip)
+	}
+
+	if trip == nil {
+		return nil
 	}
 
 	switch e.Type {
-	case EventTripStart:
-		scooter.StartRide()
-	case EventTripEnd:
-		scooter.StopRide()
 	case EventLocation:
-		scooter.UpdateLocation(e.Lat, e.Lng)
+		trip.Extend(scooter.Location())
+	case EventTripEnd:
+		trip.Close(scooter.Location(), e.ReceivedAt)
+		s.priceTrip(trip)
+	}
+
+	return s.repo.UpdateTrip(ctx, *trip)
+}
+
+// priceTrip attaches the fare to a closed trip. A trip that cannot be priced,
+// e.g. because no tariff covers it, is still closed and left for billing to
+// settle.
+func (s *service) priceTrip(trip *Trip) {
+	if s.pricer == nil {
+		return
+	}
+
+	fare, err := s.pricer.Price(*trip)
+	if err != nil {
+		log.Printf("cannot price trip %s: %v", trip.ID, err)
+		return
+	}
+
+	trip.Fare = &fare
+}
+
+// ChangeStatus lets an operator move a scooter between statuses, e.g. to pull
+// a broken scooter out of circulation for maintenance.
+func (s *service) ChangeStatus(ctx context.Context, change StatusChange) (Scooter, error) {
+	if !IsOperator(ctx) {
+		return Scooter{}, ErrOperatorOnly
+	}
+
+	err := s.validate(OpChangeStatus, change)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	unlock := s.locks.lock(change.ScooterID)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, change.ScooterID)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	reserved := scooter.Status == StatusReserved
+
+	err = scooter.SetStatus(change.Status)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	scooter.CheckBattery(s.lowBattery)
+
+	if reserved {
+		err = s.repo.DeleteReservation(ctx, scooter.ID)
+		if err != nil {
+			return Scooter{}, err
+		}
+	}
+
+	err = s.saveScooter(ctx, &scooter)
+	if err != nil {
+		return Scooter{}, err
+	}
+
+	return scooter, nil
+}
+
+// checkReservation fails unless the scooter reservation is held by clientID or
+// has already expired.
+func (s *service) checkReservation(ctx context.Context, scooterID uuid.UUID, clientID string) error {
+	res, err := s.repo.GetReservation(ctx, scooterID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if res.ClientID != clientID && !res.Expired(time.Now()) {
+		return ErrReservedByOther
+	}
+
+	return nil
+}
+
+// ReserveScooter holds a free scooter for the calling client for the
+// configured reservation TTL.
+func (s *service) ReserveScooter(ctx context.Context, id uuid.UUID) (Reservation, error) {
+	err := s.validate(OpGetScooter, id)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	clientID, _ := ClientID(ctx)
+	if clientID == "" {
+		return Reservation{}, ErrMissingClientID
+	}
+
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, id)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	err = scooter.Reserve()
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	res := Reservation{ScooterID: id, ClientID: clientID}
+	res.GenCreateVals(s.reservationTTL)
+
+	err = s.repo.CreateReservation(ctx, res)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	err = s.saveScooter(ctx, &scooter)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// ReleaseExpiredReservations frees the scooters whose reservation has expired
+// and returns how many were released.
+func (s *service) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	expired, err := s.repo.FindExpiredReservations(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var released int
+	for _, res := range expired {
+		err = s.releaseReservation(ctx, res.ScooterID)
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+func (s *service) releaseReservation(ctx context.Context, scooterID uuid.UUID) error {
+	unlock := s.locks.lock(scooterID)
+	defer unlock()
+
+	scooter, err := s.repo.GetScooter(ctx, scooterID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteReservation(ctx, scooterID)
+	if err != nil {
+		return err
+	}
+
+	if scooter.Status != StatusReserved {
+		return nil
+	}
+
+	scooter.Release()
+	scooter.CheckBattery(s.lowBattery)
+
+	return s.saveScooter(ctx, &scooter)
+}
+
+// SweepReservations releases expired reservations every interval until ctx is
+// done.
+func SweepReservations(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.ReleaseExpiredReservations(ctx)
+			if err != nil {
+				log.Printf("reservation sweeper error: %v", err)
+				continue
+			}
+
+			if n > 0 {
+				log.Printf("reservation sweeper: released %d scooters", n)
+			}
+		}
+	}
+}
+
+// checkZones records a violation for every active zone rule broken by a
+// location or trip_end event. prev is the scooter state before the event was
+// applied: the riding speed is estimated from its last location fix, and is
+// unknown, taken as 0, until the scooter has reported one.
+func (s *service) checkZones(ctx context.Context, prev, scooter Scooter, trip *Trip, e Event) error {
+	if e.Type != EventLocation && e.Type != EventTripEnd {
+		return nil
+	}
+
+	p := scooter.Location()
+	zones, err := s.repo.FindZonesContaining(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	var kmh float64
+	if prev.LocatedAt != nil {
+		kmh = speed(prev.Location(), p, e.OccurredAt.Sub(*prev.LocatedAt))
 	}
 
-	return s.repo.UpdateScooter(ctx, scooter)
+	for _, z := range zones {
+		if !z.Violated(e.Type, kmh) {
+			continue
+		}
+
+		v := Violation{
+			ZoneID:     z.ID,
+			ZoneName:   z.Name,
+			Rule:       z.Rule,
+			ScooterID:  scooter.ID,
+			EventID:    e.ID,
+			Lat:        p.Lat,
+			Lng:        p.Lng,
+			Speed:      kmh,
+			OccurredAt: e.OccurredAt,
+		}
+		v.GenID()
+
+		if trip != nil {
+			v.TripID = &trip.ID
+		}
+
+		err = s.repo.CreateViolation(ctx, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) CreateZone(ctx context.Context, z Zone) (Zone, error) {
+	if !IsOperator(ctx) {
+		return Zone{}, ErrOperatorOnly
+	}
+
+	err := s.validate(OpSaveZone, z)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	z.ID = uuid.Nil
+	z.GenCreateVals()
+
+	err = s.repo.CreateZone(ctx, z)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	return z, nil
+}
+
+func (s *service) UpdateZone(ctx context.Context, z Zone) (Zone, error) {
+	if !IsOperator(ctx) {
+		return Zone{}, ErrOperatorOnly
+	}
+
+	err := s.validate(OpGetZone, z.ID)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	err = s.validate(OpSaveZone, z)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	current, err := s.repo.GetZone(ctx, z.ID)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	z.CreatedAt = current.CreatedAt
+	z.AuditUpdate()
+
+	err = s.repo.UpdateZone(ctx, z)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	return z, nil
+}
+
+func (s *service) GetZone(ctx context.Context, id uuid.UUID) (Zone, error) {
+	err := s.validate(OpGetZone, id)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	return s.repo.GetZone(ctx, id)
+}
+
+func (s *service) DeleteZone(ctx context.Context, id uuid.UUID) error {
+	if !IsOperator(ctx) {
+		return ErrOperatorOnly
+	}
+
+	err := s.validate(OpGetZone, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteZone(ctx, id)
+}
+
+func (s *service) ListZones(ctx context.Context) ([]Zone, error) {
+	return s.repo.ListZones(ctx)
+}
+
+// FindViolations returns the zone violations of a scooter or a trip. Riders
+// may only look into the violations of their own trips.
+func (s *service) FindViolations(ctx context.Context, qry ViolationQuery) ([]Violation, error) {
+	if !IsOperator(ctx) {
+		if qry.TripID == uuid.Nil {
+			return nil, ErrOperatorOnly
+		}
+
+		_, err := s.visibleTrip(ctx, qry.TripID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.FindViolations(ctx, qry)
+}
+
+// FindEvents returns the event history of a scooter, e.g. to look into the
+// fare of a trip. Events follow riders around, so they are for operators
+// only.
+func (s *service) FindEvents(ctx context.Context, qry EventQuery) ([]Event, error) {
+	if !IsOperator(ctx) {
+		return nil, ErrOperatorOnly
+	}
+
+	err := s.validate(OpFindEvents, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.FindEvents(ctx, qry)
+}
+
+// Track assembles the location events of a scooter, late ones included, into
+// its path ordered by device time. Tracks follow riders around, so they are
+// for operators only.
+func (s *service) Track(ctx context.Context, qry TrackQuery) (Track, error) {
+	if !IsOperator(ctx) {
+		return Track{}, ErrOperatorOnly
+	}
+
+	err := s.validate(OpTrack, qry)
+	if err != nil {
+		return Track{}, err
+	}
+
+	events, err := s.repo.FindEvents(ctx, EventQuery{
+		ScooterID: qry.ScooterID,
+		Type:      EventLocation,
+		From:      qry.From,
+		To:        qry.To,
+		Limit:     MaxTrackPoints + 1,
+		Latest:    true,
+	})
+	if err != nil {
+		return Track{}, err
+	}
+
+	// One event more than the cap tells whether earlier ones were left out.
+	track := Track{ScooterID: qry.ScooterID, Truncated: len(events) > MaxTrackPoints}
+	if track.Truncated {
+		events = events[1:]
+	}
+
+	track.Points = make([]TrackPoint, 0, len(events))
+	for _, e := range events {
+		track.Points = append(track.Points, TrackPoint{Point: Point{Lat: e.Lat, Lng: e.Lng}, At: e.OccurredAt})
+	}
+
+	track.Points = Simplify(track.Points, qry.Tolerance)
+
+	return track, nil
+}
+
+// GetTrip returns a trip. Riders only see their own trips, those of other
+// clients are reported as not found.
+func (s *service) GetTrip(ctx context.Context, id uuid.UUID) (Trip, error) {
+	err := s.validate(OpGetTrip, id)
+	if err != nil {
+		return Trip{}, err
+	}
+
+	return s.visibleTrip(ctx, id)
+}
+
+// FindTrips returns the trips matching the query. Riders only get their own
+// trips and cannot ask for those of another client.
+func (s *service) FindTrips(ctx context.Context, qry TripQuery) ([]Trip, error) {
+	err := s.validate(OpFindTrips, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsOperator(ctx) {
+		clientID, _ := ClientID(ctx)
+		if clientID == "" {
+			return nil, ErrMissingClientID
+		}
+
+		if qry.ClientID != "" && qry.ClientID != clientID {
+			return nil, ErrOperatorOnly
+		}
+
+		qry.ClientID = clientID
+	}
+
+	return s.repo.FindTrips(ctx, qry)
+}
+
+// visibleTrip returns the trip if the caller may see it: operators see every
+// trip, riders only their own.
+func (s *service) visibleTrip(ctx context.Context, id uuid.UUID) (Trip, error) {
+	trip, err := s.repo.GetTrip(ctx, id)
+	if err != nil {
+		return Trip{}, err
+	}
+
+	if IsOperator(ctx) {
+		return trip, nil
+	}
+
+	clientID, _ := ClientID(ctx)
+	if clientID == "" || trip.ClientID != clientID {
+		return Trip{}, ErrNotFound
+	}
+
+	return trip, nil
 }
 
 // SetValidator lets you replace the default validator with a custom one.
@@ -92,3 +1175,20 @@ func (s *service) ReportEvent(ctx context.Context, e Event) error {
 func (s *service) SetValidator(v Validator) {
 	s.validate = v
 }
+
+// scooterLocks serializes event processing per scooter so that two concurrent
+// events (e.g. a double-tapped trip_start) cannot both pass the transition
+// check against the same scooter state. The locks are per process; events
+// are also applied under Repo.LockScooter, which covers the other instances
+// sharing the store.
+type scooterLocks struct {
+	m sync.Map
+}
+
+func (l *scooterLocks) lock(id uuid.UUID) (unlock func()) {
+	v, _ := l.m.LoadOrStore(id, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+
+	return mu.Unlock
+}
@@ -1,8 +1,14 @@
 package telemetry_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/adrianpk/rida/internal/repo/mem"
 	"github.com/adrianpk/rida/internal/telemetry"
@@ -16,12 +22,16 @@ func TestService_ReportEvent(t *testing.T) {
 
 	scooterID := uuid.New()
 	initialScooter := telemetry.Scooter{
-		ID:     scooterID,
-		Status: telemetry.StatusFree,
-		Lat:    45.0,
-		Lng:    -75.0,
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
 	}
 
+	occupiedScooter := initialScooter
+	occupiedScooter.Status = telemetry.StatusOccupied
+
 	tests := []struct {
 		name       string
 		initial    map[uuid.UUID]telemetry.Scooter
@@ -47,7 +57,7 @@ func TestService_ReportEvent(t *testing.T) {
 		},
 		{
 			name:    "trip end updates status to free",
-			initial: initialData(initialScooter),
+			initial: initialData(occupiedScooter),
 			args: args{
 				event: telemetry.Event{
 					ScooterID: scooterID,
@@ -61,7 +71,7 @@ func TestService_ReportEvent(t *testing.T) {
 		},
 		{
 			name:    "location update changes lat/lng",
-			initial: initialData(initialScooter),
+			initial: initialData(occupiedScooter),
 			args: args{
 				event: telemetry.Event{
 					ScooterID: scooterID,
@@ -70,7 +80,7 @@ func TestService_ReportEvent(t *testing.T) {
 					Lng:       -76.0,
 				},
 			},
-			wantStatus: telemetry.StatusFree,
+			wantStatus: telemetry.StatusOccupied,
 			wantLat:    46.0,
 			wantLng:    -76.0,
 			wantErr:    false,
@@ -121,7 +131,8 @@ func TestService_ReportEvent(t *testing.T) {
 				tt.args.event.ScooterID = scooterID
 			}
 
-			err := svc.ReportEvent(context.Background(), tt.args.event)
+			ctx := telemetry.WithClientID(context.Background(), "rider-1")
+			_, err := svc.ReportEvent(ctx, tt.args.event)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ReportEvent() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -148,6 +159,1382 @@ func TestService_ReportEvent(t *testing.T) {
 	}
 }
 
-func initialData(scooter telemetry.Scooter) map[uuid.UUID]telemetry.Scooter {
-	return map[uuid.UUID]telemetry.Scooter{scooter.ID: scooter}
+func TestService_ReportEventInvalidTransition(t *testing.T) {
+	scooterID := uuid.New()
+
+	tests := []struct {
+		name   string
+		status telemetry.Status
+		event  telemetry.EventType
+	}{
+		{"trip start on occupied scooter", telemetry.StatusOccupied, telemetry.EventTripStart},
+		{"trip end on free scooter", telemetry.StatusFree, telemetry.EventTripEnd},
+		{"location on free scooter", telemetry.StatusFree, telemetry.EventLocation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: tt.status}))
+			svc := telemetry.NewService(repo)
+
+			ctx := telemetry.WithClientID(context.Background(), "rider-1")
+			_, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: tt.event})
+			if !errors.Is(err, telemetry.ErrInvalidTransition) {
+				t.Fatalf("expected ErrInvalidTransition, got %v", err)
+			}
+
+			if got := repo.Scooters()[scooterID].Status; got != tt.status {
+				t.Errorf("status changed to %q, want %q", got, tt.status)
+			}
+
+			if n := len(repo.Events()); n != 0 {
+				t.Errorf("expected rejected event not to be stored, got %d events", n)
+			}
+		})
+	}
+}
+
+func TestService_ReportEventTrip(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo)
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	events := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.001, Lng: -75.0},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.002, Lng: -75.0},
+		{ScooterID: scooterID, Type: telemetry.EventTripEnd},
+	}
+
+	for _, e := range events {
+		if _, err := svc.ReportEvent(ctx, e); err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+	}
+
+	trips, err := svc.FindTrips(ctx, telemetry.TripQuery{ScooterID: scooterID})
+	if err != nil {
+		t.Fatalf("FindTrips() error = %v", err)
+	}
+
+	if len(trips) != 1 {
+		t.Fatalf("expected 1 trip, got %d", len(trips))
+	}
+
+	trip := trips[0]
+	if trip.Active() {
+		t.Errorf("expected trip to be closed")
+	}
+
+	if len(trip.Path) != 3 {
+		t.Errorf("expected 3 path points, got %d", len(trip.Path))
+	}
+
+	wantEnd := telemetry.Point{Lat: 45.002, Lng: -75.0}
+	if trip.End == nil || *trip.End != wantEnd {
+		t.Errorf("trip end = %v, want %v", trip.End, wantEnd)
+	}
+
+	if trip.Distance < 220 || trip.Distance > 225 {
+		t.Errorf("trip distance = %v, want ~222m", trip.Distance)
+	}
+}
+
+func TestService_TripAccess(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo)
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+	other := telemetry.WithClientID(context.Background(), "rider-2")
+
+	for _, typ := range []telemetry.EventType{telemetry.EventTripStart, telemetry.EventTripEnd} {
+		if _, err := svc.ReportEvent(rider, telemetry.Event{ScooterID: scooterID, Type: typ}); err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", typ, err)
+		}
+	}
+
+	trips, err := svc.FindTrips(rider, telemetry.TripQuery{})
+	if err != nil || len(trips) != 1 {
+		t.Fatalf("FindTrips() by owner: expected 1 trip, got %d (err: %v)", len(trips), err)
+	}
+
+	trips, err = svc.FindTrips(other, telemetry.TripQuery{ScooterID: scooterID})
+	if err != nil || len(trips) != 0 {
+		t.Errorf("FindTrips() by another rider: expected no trips, got %d (err: %v)", len(trips), err)
+	}
+
+	_, err = svc.FindTrips(other, telemetry.TripQuery{ClientID: "rider-1"})
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("FindTrips() for another client: expected ErrOperatorOnly, got %v", err)
+	}
+
+	_, err = svc.FindTrips(context.Background(), telemetry.TripQuery{})
+	if !errors.Is(err, telemetry.ErrMissingClientID) {
+		t.Errorf("FindTrips() without a client: expected ErrMissingClientID, got %v", err)
+	}
+
+	trips, err = svc.FindTrips(telemetry.WithOperator(context.Background()), telemetry.TripQuery{ClientID: "rider-1"})
+	if err != nil || len(trips) != 1 {
+		t.Fatalf("FindTrips() by operator: expected 1 trip, got %d (err: %v)", len(trips), err)
+	}
+
+	id := trips[0].ID
+	if _, err = svc.GetTrip(rider, id); err != nil {
+		t.Errorf("GetTrip() by owner error = %v", err)
+	}
+
+	if _, err = svc.GetTrip(other, id); !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("GetTrip() by another rider: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestService_ReportEventFare(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	tariffs := telemetry.Tariffs{{City: "flat", Currency: "CAD", UnlockFee: 100, PerKm: 1000}}
+	svc := telemetry.NewService(repo, telemetry.WithPricer(tariffs))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	events := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.002, Lng: -75.0},
+	}
+
+	for _, e := range events {
+		res, err := svc.ReportEvent(ctx, e)
+		if err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+
+		if res.Trip != nil {
+			t.Errorf("ReportEvent(%s) returned a trip, want none before trip_end", e.Type)
+		}
+	}
+
+	res, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd})
+	if err != nil {
+		t.Fatalf("ReportEvent(trip_end) error = %v", err)
+	}
+
+	if res.Status != telemetry.StatusFree || res.Trip == nil || res.Trip.Fare == nil {
+		t.Fatalf("expected free scooter and priced trip, got %+v", res)
+	}
+
+	want := telemetry.Fare{Tariff: "flat", Currency: "CAD", UnlockFee: 100, Minutes: 1, DistanceCharge: 222, Total: 322}
+	if *res.Trip.Fare != want {
+		t.Errorf("fare = %+v, want %+v", *res.Trip.Fare, want)
+	}
+
+	stored, err := svc.GetTrip(ctx, res.Trip.ID)
+	if err != nil {
+		t.Fatalf("GetTrip() error = %v", err)
+	}
+
+	if stored.Fare == nil || *stored.Fare != want {
+		t.Errorf("stored fare = %+v, want %+v", stored.Fare, want)
+	}
+}
+
+func TestService_ReportEvents(t *testing.T) {
+	free := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	other := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	repo := mem.NewTelemetryRepo(initialData(free, other))
+	svc := telemetry.NewService(repo)
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	events := []telemetry.Event{
+		{ScooterID: free.ID, Type: telemetry.EventTripStart},
+		{ScooterID: other.ID, Type: telemetry.EventTripEnd},
+		{ScooterID: free.ID, Type: telemetry.EventLocation, Lat: 45.43, Lng: -75.70},
+		{ScooterID: uuid.New(), Type: telemetry.EventLocation},
+		{ScooterID: free.ID, Type: telemetry.EventTripEnd},
+	}
+
+	items, err := svc.ReportEvents(ctx, events)
+	if err != nil {
+		t.Fatalf("ReportEvents() error = %v", err)
+	}
+
+	if len(items) != len(events) {
+		t.Fatalf("expected %d items, got %d", len(events), len(items))
+	}
+
+	wantErrs := []error{nil, telemetry.ErrInvalidTransition, nil, telemetry.ErrNotFound, nil}
+	for i, item := range items {
+		if !errors.Is(item.Err, wantErrs[i]) || (wantErrs[i] == nil && item.Err != nil) {
+			t.Errorf("item %d: expected error %v, got %v", i, wantErrs[i], item.Err)
+		}
+	}
+
+	if items[4].Result.Status != telemetry.StatusFree || items[4].Result.Trip == nil {
+		t.Errorf("expected the ride to be closed in batch order, got %+v", items[4].Result)
+	}
+
+	got, _ := repo.GetScooter(context.Background(), free.ID)
+	if got.Lat != 45.43 || got.Status != telemetry.StatusFree {
+		t.Errorf("expected scooter to end free at the last reported location, got %+v", got)
+	}
+
+	_, err = svc.ReportEvents(ctx, make([]telemetry.Event, telemetry.MaxBatchSize+1))
+	if !errors.Is(err, telemetry.ErrInvalidBatch) {
+		t.Errorf("expected ErrInvalidBatch for oversized batch, got %v", err)
+	}
+}
+
+func TestService_ReportEventIdempotent(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo)
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	start := telemetry.Event{ID: uuid.New(), ScooterID: scooterID, Type: telemetry.EventTripStart}
+	first, err := svc.ReportEvent(ctx, start)
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	// A trip_start applied twice would be rejected on the occupied scooter.
+	retry, err := svc.ReportEvent(ctx, start)
+	if err != nil {
+		t.Fatalf("retried ReportEvent() error = %v", err)
+	}
+
+	if !retry.Replayed || retry.EventID != first.EventID || retry.Status != telemetry.StatusOccupied {
+		t.Errorf("expected the original result replayed, got %+v", retry)
+	}
+
+	keyed := telemetry.WithIdempotencyKey(ctx, "move-1")
+	move := telemetry.Event{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.001, Lng: -75.0}
+	first, err = svc.ReportEvent(keyed, move)
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	retry, err = svc.ReportEvent(keyed, move)
+	if err != nil || !retry.Replayed || retry.EventID != first.EventID {
+		t.Errorf("expected the keyed event replayed, got %+v, %v", retry, err)
+	}
+
+	trip, err := repo.GetActiveTrip(ctx, scooterID)
+	if err != nil {
+		t.Fatalf("GetActiveTrip() error = %v", err)
+	}
+
+	if len(trip.Path) != 2 {
+		t.Errorf("expected the retried location to be tracked once, got path %v", trip.Path)
+	}
+
+	_, err = svc.ReportEvent(keyed, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd})
+	if !errors.Is(err, telemetry.ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused for a different event, got %v", err)
+	}
+
+	other := telemetry.WithIdempotencyKey(telemetry.WithClientID(context.Background(), "rider-2"), "move-1")
+	_, err = svc.ReportEvent(other, move)
+	if !errors.Is(err, telemetry.ErrNotRideOwner) {
+		t.Errorf("expected keys to be scoped by client, got %v", err)
+	}
+}
+
+func TestService_ReportEventDedupWindow(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Battery: 100,
+	}))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+	battery := 90
+	e := telemetry.Event{ID: uuid.New(), ScooterID: scooterID, Type: telemetry.EventBattery, Battery: &battery}
+
+	svc := telemetry.NewService(repo, telemetry.WithDedupWindow(0))
+	if _, err := svc.ReportEvent(ctx, e); err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	// Without deduplication the retry reaches the repo, which refuses the
+	// duplicated event ID.
+	_, err := svc.ReportEvent(ctx, e)
+	if !errors.Is(err, telemetry.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists without dedup window, got %v", err)
+	}
+
+	svc = telemetry.NewService(repo, telemetry.WithDedupWindow(time.Minute))
+	e.ID = uuid.New()
+	if _, err := svc.ReportEvent(ctx, e); err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	n, err := svc.PurgeProcessedEvents(ctx)
+	if err != nil || n != 0 {
+		t.Errorf("expected nothing purged within the window, got %d, %v", n, err)
+	}
+
+	svc = telemetry.NewService(repo, telemetry.WithDedupWindow(time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	n, err = svc.PurgeProcessedEvents(ctx)
+	if err != nil || n != 1 {
+		t.Errorf("expected the expired record purged, got %d, %v", n, err)
+	}
+}
+
+func TestService_ReportEventLate(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo, telemetry.WithMaxClockSkew(time.Minute))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+	now := time.Now()
+
+	_, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart, OccurredAt: now.Add(-10 * time.Minute)})
+	if err != nil {
+		t.Fatalf("ReportEvent(trip_start) error = %v", err)
+	}
+
+	res, err := svc.ReportEvent(ctx, telemetry.Event{
+		ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.002, Lng: -75.0, OccurredAt: now.Add(-time.Minute),
+	})
+	if err != nil || res.Stale {
+		t.Fatalf("ReportEvent() = %+v, %v, want applied", res, err)
+	}
+
+	late := telemetry.Event{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.001, Lng: -75.0, OccurredAt: now.Add(-2 * time.Minute)}
+	res, err = svc.ReportEvent(ctx, late)
+	if err != nil {
+		t.Fatalf("ReportEvent(late) error = %v", err)
+	}
+
+	if !res.Stale || res.Status != telemetry.StatusOccupied {
+		t.Errorf("expected a stale result with the current status, got %+v", res)
+	}
+
+	got, _ := repo.GetScooter(ctx, scooterID)
+	if got.Lat != 45.002 {
+		t.Errorf("expected the late location not to move the scooter back, got lat %v", got.Lat)
+	}
+
+	events := repo.Events()
+	last := events[len(events)-1]
+	if !last.Stale || !last.OccurredAt.Equal(late.OccurredAt) || last.ReceivedAt.Before(now) {
+		t.Errorf("expected the late event recorded as stale with both times, got %+v", last)
+	}
+
+	res, err = svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd, OccurredAt: now.Add(-90 * time.Second)})
+	if err != nil || res.Stale || res.Status != telemetry.StatusFree {
+		t.Errorf("expected ride events not to be checked for staleness, got %+v, %v", res, err)
+	}
+
+	if res.Trip == nil || res.Trip.EndedAt == nil || res.Trip.StartedAt.Before(now) || res.Trip.EndedAt.Before(res.Trip.StartedAt) {
+		t.Errorf("expected the trip timed by the server clock, got %+v", res.Trip)
+	}
+
+	_, err = svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventLocation, OccurredAt: now.Add(2 * time.Minute)})
+	if !errors.Is(err, telemetry.ErrInvalidEvent) {
+		t.Errorf("expected ErrInvalidEvent beyond the clock skew, got %v", err)
+	}
+}
+
+func TestService_FindEvents(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	// Ride events are only backdated within the allowed skew.
+	svc := telemetry.NewService(repo, telemetry.WithMaxClockSkew(time.Hour))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+
+	reports := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart, OccurredAt: start},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.002, Lng: -75.0, OccurredAt: start.Add(2 * time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.001, Lng: -75.0, OccurredAt: start.Add(time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventTripEnd, OccurredAt: start.Add(3 * time.Minute)},
+	}
+
+	for _, e := range reports {
+		_, err := svc.ReportEvent(ctx, e)
+		if err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+	}
+
+	_, err := svc.FindEvents(ctx, telemetry.EventQuery{ScooterID: scooterID})
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("FindEvents() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	ctx = telemetry.WithOperator(ctx)
+	events, err := svc.FindEvents(ctx, telemetry.EventQuery{ScooterID: scooterID, Limit: telemetry.DefaultEventLimit})
+	if err != nil {
+		t.Fatalf("FindEvents() error = %v", err)
+	}
+
+	if len(events) != 4 || events[1].Lat != 45.001 || !events[1].Stale || events[3].Type != telemetry.EventTripEnd {
+		t.Errorf("expected all events by device time, the late one included, got %+v", events)
+	}
+
+	events, _ = svc.FindEvents(ctx, telemetry.EventQuery{ScooterID: scooterID, Type: telemetry.EventLocation, Limit: 1})
+	if len(events) != 1 || events[0].Lat != 45.001 {
+		t.Errorf("expected the first location event, got %+v", events)
+	}
+
+	events, _ = svc.FindEvents(ctx, telemetry.EventQuery{ScooterID: scooterID, From: start.Add(time.Minute), To: start.Add(3 * time.Minute), Limit: 10})
+	if len(events) != 2 {
+		t.Errorf("expected the events in [from, to), got %+v", events)
+	}
+
+	events, _ = svc.FindEvents(ctx, telemetry.EventQuery{ScooterID: uuid.New(), Limit: 10})
+	if len(events) != 0 {
+		t.Errorf("expected no events for another scooter, got %+v", events)
+	}
+
+	invalid := []telemetry.EventQuery{
+		{Limit: 10},
+		{ScooterID: scooterID, Type: "jump", Limit: 10},
+		{ScooterID: scooterID, From: start, To: start.Add(-time.Minute), Limit: 10},
+		{ScooterID: scooterID, Limit: telemetry.MaxEventLimit + 1},
+	}
+
+	for _, qry := range invalid {
+		_, err = svc.FindEvents(ctx, qry)
+		if telemetry.ErrorCode(err) != "invalid_query" && !errors.Is(err, telemetry.ErrInvalidID) {
+			t.Errorf("FindEvents(%+v) error = %v, want a validation error", qry, err)
+		}
+	}
+}
+
+func TestService_Track(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo, telemetry.WithMaxClockSkew(time.Minute))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	battery := 90
+
+	reports := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart, Lat: 45.0, Lng: -75.0, OccurredAt: start},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.001, Lng: -75.0, OccurredAt: start.Add(time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.003, Lng: -75.0, OccurredAt: start.Add(3 * time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.002, Lng: -75.00001, OccurredAt: start.Add(2 * time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventBattery, Battery: &battery, OccurredAt: start.Add(4 * time.Minute)},
+	}
+
+	for _, e := range reports {
+		_, err := svc.ReportEvent(ctx, e)
+		if err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+	}
+
+	qry := telemetry.TrackQuery{ScooterID: scooterID}
+	_, err := svc.Track(ctx, qry)
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("expected tracks to be for operators only, got %v", err)
+	}
+
+	operator := telemetry.WithOperator(ctx)
+	track, err := svc.Track(operator, qry)
+	if err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+
+	if len(track.Points) != 3 || track.Points[1].Lat != 45.002 || !track.Points[1].At.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("expected the location events by device time, the late one included, got %+v", track.Points)
+	}
+
+	qry.Tolerance = 10
+	track, _ = svc.Track(operator, qry)
+	if len(track.Points) != 2 {
+		t.Errorf("expected the track simplified to its ends, got %+v", track.Points)
+	}
+
+	qry = telemetry.TrackQuery{ScooterID: scooterID, From: start.Add(2 * time.Minute)}
+	track, _ = svc.Track(operator, qry)
+	if len(track.Points) != 2 || track.Points[0].Lat != 45.002 {
+		t.Errorf("expected the track from the given time, got %+v", track.Points)
+	}
+
+	_, err = svc.Track(operator, telemetry.TrackQuery{ScooterID: scooterID, Tolerance: -1})
+	if telemetry.ErrorCode(err) != "invalid_query" {
+		t.Errorf("expected an invalid tolerance error, got %v", err)
+	}
+}
+
+func TestService_TrackTruncated(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: telemetry.StatusFree, Battery: 100}))
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+	start := time.Now().Add(-24 * time.Hour)
+
+	for i := 0; i <= telemetry.MaxTrackPoints; i++ {
+		err := repo.StoreEvent(operator, telemetry.Event{
+			ID:         uuid.New(),
+			ScooterID:  scooterID,
+			Type:       telemetry.EventLocation,
+			Lat:        45.0 + float64(i)*1e-5,
+			Lng:        -75.0,
+			OccurredAt: start.Add(time.Duration(i) * time.Second),
+		})
+		if err != nil {
+			t.Fatalf("StoreEvent() error = %v", err)
+		}
+	}
+
+	track, err := svc.Track(operator, telemetry.TrackQuery{ScooterID: scooterID})
+	if err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+
+	if !track.Truncated || len(track.Points) != telemetry.MaxTrackPoints {
+		t.Fatalf("expected a truncated track of %d points, got %d, truncated %v", telemetry.MaxTrackPoints, len(track.Points), track.Truncated)
+	}
+
+	if first := track.Points[0].At; !first.Equal(start.Add(time.Second)) {
+		t.Errorf("expected the latest points to be kept, the track starts at %v", first)
+	}
+
+	track, _ = svc.Track(operator, telemetry.TrackQuery{ScooterID: scooterID, From: start.Add(time.Second)})
+	if track.Truncated || len(track.Points) != telemetry.MaxTrackPoints {
+		t.Errorf("expected a full track of %d points, got %d, truncated %v", telemetry.MaxTrackPoints, len(track.Points), track.Truncated)
+	}
+}
+
+func TestService_ReportEventRideClock(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: telemetry.StatusFree, Battery: 100}))
+	tariffs := telemetry.Tariffs{{City: "flat", Currency: "CAD", UnlockFee: 100, PerMinute: 30}}
+	svc := telemetry.NewService(repo, telemetry.WithPricer(tariffs), telemetry.WithMaxClockSkew(time.Minute))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+	now := time.Now()
+
+	_, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+	if err != nil {
+		t.Fatalf("ReportEvent(trip_start) error = %v", err)
+	}
+
+	res, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd, OccurredAt: now.Add(-48 * time.Hour)})
+	if err != nil {
+		t.Fatalf("ReportEvent(trip_end) error = %v", err)
+	}
+
+	trip := res.Trip
+	if trip == nil || trip.EndedAt == nil || trip.EndedAt.Before(trip.StartedAt) || trip.StartedAt.Before(now) {
+		t.Fatalf("expected the trip timed by the server clock, got %+v", trip)
+	}
+
+	if trip.Fare == nil || trip.Fare.Minutes != 1 || trip.Fare.TimeCharge != 30 {
+		t.Errorf("expected a backdated trip_end not to cut the fare, got %+v", trip.Fare)
+	}
+
+	events := repo.Events()
+	if last := events[len(events)-1]; last.OccurredAt.Before(now) {
+		t.Errorf("expected the ride event time clamped to its reception, got %v", last.OccurredAt)
+	}
+}
+
+func TestService_ReportEventRideOwner(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: telemetry.StatusFree, Battery: 100}))
+	svc := telemetry.NewService(repo)
+
+	owner := telemetry.WithClientID(context.Background(), "rider-1")
+	other := telemetry.WithClientID(context.Background(), "rider-2")
+
+	_, err := svc.ReportEvent(context.Background(), telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+	if !errors.Is(err, telemetry.ErrMissingClientID) {
+		t.Fatalf("expected ErrMissingClientID for anonymous trip start, got %v", err)
+	}
+
+	_, err = svc.ReportEvent(owner, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+	if err != nil {
+		t.Fatalf("trip start error = %v", err)
+	}
+
+	battery := 50
+	for _, et := range []telemetry.EventType{telemetry.EventLocation, telemetry.EventBattery, telemetry.EventTripEnd} {
+		_, err = svc.ReportEvent(other, telemetry.Event{ScooterID: scooterID, Type: et, Lat: 1, Lng: 1, Battery: &battery})
+		if !errors.Is(err, telemetry.ErrNotRideOwner) {
+			t.Errorf("%s from other client: expected ErrNotRideOwner, got %v", et, err)
+		}
+	}
+
+	router := telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}, "operator")
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/events",
+		strings.NewReader(`{"scooterId":"`+scooterID.String()+`","type":"location","lat":1,"lng":1}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-API-Key", "key")
+	r.Header.Set("X-Client-ID", "rider-2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"code":"not_ride_owner"`) {
+		t.Errorf("location from other client: expected 403 not_ride_owner, got %d %s", w.Code, w.Body.String())
+	}
+
+	if got := repo.Scooters()[scooterID]; got.Status != telemetry.StatusOccupied || got.Lat != 0 || got.Battery != 100 {
+		t.Errorf("scooter changed by foreign events: %+v", got)
+	}
+
+	_, err = svc.ReportEvent(owner, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd})
+	if err != nil {
+		t.Fatalf("trip end from owner error = %v", err)
+	}
+
+	_, err = svc.ReportEvent(other, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+	if err != nil {
+		t.Errorf("trip start by other client after trip closed error = %v", err)
+	}
+}
+
+func TestService_ReportEventBattery(t *testing.T) {
+	level := func(v int) *int { return &v }
+
+	tests := []struct {
+		name       string
+		status     telemetry.Status
+		battery    int
+		event      telemetry.Event
+		wantStatus telemetry.Status
+		wantErr    bool
+	}{
+		{
+			name:       "free scooter below threshold becomes low battery",
+			status:     telemetry.StatusFree,
+			battery:    50,
+			event:      telemetry.Event{Type: telemetry.EventBattery, Battery: level(10)},
+			wantStatus: telemetry.StatusLowBattery,
+		},
+		{
+			name:       "occupied scooter below threshold keeps riding",
+			status:     telemetry.StatusOccupied,
+			battery:    50,
+			event:      telemetry.Event{Type: telemetry.EventBattery, Battery: level(10)},
+			wantStatus: telemetry.StatusOccupied,
+		},
+		{
+			name:       "trip end below threshold becomes low battery",
+			status:     telemetry.StatusOccupied,
+			battery:    10,
+			event:      telemetry.Event{Type: telemetry.EventTripEnd},
+			wantStatus: telemetry.StatusLowBattery,
+		},
+		{
+			name:       "recharged scooter becomes free",
+			status:     telemetry.StatusLowBattery,
+			battery:    10,
+			event:      telemetry.Event{Type: telemetry.EventBattery, Battery: level(90)},
+			wantStatus: telemetry.StatusFree,
+		},
+		{
+			name:       "low battery scooter cannot be rented",
+			status:     telemetry.StatusLowBattery,
+			battery:    10,
+			event:      telemetry.Event{Type: telemetry.EventTripStart},
+			wantStatus: telemetry.StatusLowBattery,
+			wantErr:    true,
+		},
+		{
+			name:       "battery event without level",
+			status:     telemetry.StatusFree,
+			battery:    50,
+			event:      telemetry.Event{Type: telemetry.EventBattery},
+			wantStatus: telemetry.StatusFree,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scooterID := uuid.New()
+			repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: tt.status, Battery: tt.battery}))
+			svc := telemetry.NewService(repo, telemetry.WithLowBatteryThreshold(20))
+
+			tt.event.ScooterID = scooterID
+			ctx := telemetry.WithClientID(context.Background(), "rider-1")
+			_, err := svc.ReportEvent(ctx, tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReportEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := repo.Scooters()[scooterID].Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestService_ChangeStatus(t *testing.T) {
+	operator := telemetry.WithOperator(context.Background())
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		status     telemetry.Status
+		battery    int
+		to         telemetry.Status
+		wantStatus telemetry.Status
+		wantErr    error
+	}{
+		{
+			name:       "free to maintenance",
+			ctx:        operator,
+			status:     telemetry.StatusFree,
+			battery:    100,
+			to:         telemetry.StatusMaintenance,
+			wantStatus: telemetry.StatusMaintenance,
+		},
+		{
+			name:       "maintenance back to free with low battery",
+			ctx:        operator,
+			status:     telemetry.StatusMaintenance,
+			battery:    5,
+			to:         telemetry.StatusFree,
+			wantStatus: telemetry.StatusLowBattery,
+		},
+		{
+			name:       "requires operator",
+			ctx:        context.Background(),
+			status:     telemetry.StatusFree,
+			battery:    100,
+			to:         telemetry.StatusMaintenance,
+			wantStatus: telemetry.StatusFree,
+			wantErr:    telemetry.ErrOperatorOnly,
+		},
+		{
+			name:       "occupied cannot be pulled",
+			ctx:        operator,
+			status:     telemetry.StatusOccupied,
+			battery:    100,
+			to:         telemetry.StatusMaintenance,
+			wantStatus: telemetry.StatusOccupied,
+			wantErr:    telemetry.ErrInvalidTransition,
+		},
+		{
+			name:       "decommissioned is terminal",
+			ctx:        operator,
+			status:     telemetry.StatusDecommissioned,
+			battery:    100,
+			to:         telemetry.StatusFree,
+			wantStatus: telemetry.StatusDecommissioned,
+			wantErr:    telemetry.ErrInvalidTransition,
+		},
+		{
+			name:       "unknown status",
+			ctx:        operator,
+			status:     telemetry.StatusFree,
+			battery:    100,
+			to:         "broken",
+			wantStatus: telemetry.StatusFree,
+			wantErr:    telemetry.ErrInvalidStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scooterID := uuid.New()
+			repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: tt.status, Battery: tt.battery}))
+			svc := telemetry.NewService(repo)
+
+			_, err := svc.ChangeStatus(tt.ctx, telemetry.StatusChange{ScooterID: scooterID, Status: tt.to})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangeStatus() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := repo.Scooters()[scooterID].Status; got != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestService_Reservation(t *testing.T) {
+	scooterID := uuid.New()
+	holder := telemetry.WithClientID(context.Background(), "rider-1")
+	other := telemetry.WithClientID(context.Background(), "rider-2")
+
+	newSvc := func(ttl time.Duration) (*mem.TelemetryRepo, telemetry.Service) {
+		repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: telemetry.StatusFree, Battery: 100}))
+		return repo, telemetry.NewService(repo, telemetry.WithReservationTTL(ttl))
+	}
+
+	t.Run("holder starts the ride", func(t *testing.T) {
+		repo, svc := newSvc(time.Minute)
+
+		res, err := svc.ReserveScooter(holder, scooterID)
+		if err != nil {
+			t.Fatalf("ReserveScooter() error = %v", err)
+		}
+
+		if res.ClientID != "rider-1" || !res.ExpiresAt.After(res.CreatedAt) {
+			t.Errorf("unexpected reservation: %+v", res)
+		}
+
+		_, err = svc.ReserveScooter(other, scooterID)
+		if !errors.Is(err, telemetry.ErrInvalidTransition) {
+			t.Errorf("second reservation: expected ErrInvalidTransition, got %v", err)
+		}
+
+		_, err = svc.ReportEvent(other, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+		if !errors.Is(err, telemetry.ErrReservedByOther) {
+			t.Errorf("trip start by other client: expected ErrReservedByOther, got %v", err)
+		}
+
+		_, err = svc.ReportEvent(holder, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+		if err != nil {
+			t.Fatalf("trip start by holder error = %v", err)
+		}
+
+		if got := repo.Scooters()[scooterID].Status; got != telemetry.StatusOccupied {
+			t.Errorf("status = %q, want %q", got, telemetry.StatusOccupied)
+		}
+
+		if _, err := repo.GetReservation(context.Background(), scooterID); !errors.Is(err, telemetry.ErrNotFound) {
+			t.Errorf("expected reservation to be consumed, got %v", err)
+		}
+	})
+
+	t.Run("expired reservation is released", func(t *testing.T) {
+		repo, svc := newSvc(time.Millisecond)
+
+		if _, err := svc.ReserveScooter(holder, scooterID); err != nil {
+			t.Fatalf("ReserveScooter() error = %v", err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		n, err := svc.ReleaseExpiredReservations(context.Background())
+		if err != nil || n != 1 {
+			t.Fatalf("ReleaseExpiredReservations() = %d, %v, want 1", n, err)
+		}
+
+		if got := repo.Scooters()[scooterID].Status; got != telemetry.StatusFree {
+			t.Errorf("status = %q, want %q", got, telemetry.StatusFree)
+		}
+	})
+
+	t.Run("requires client id", func(t *testing.T) {
+		_, svc := newSvc(time.Minute)
+
+		_, err := svc.ReserveScooter(context.Background(), scooterID)
+		if !errors.Is(err, telemetry.ErrMissingClientID) {
+			t.Errorf("expected ErrMissingClientID, got %v", err)
+		}
+	})
+}
+
+func TestService_FindScootersPagination(t *testing.T) {
+	var scooters []telemetry.Scooter
+	for i := 0; i < telemetry.MaxPageSize+5; i++ {
+		scooters = append(scooters, telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45, Lng: -75, Battery: 80})
+	}
+
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooters...)))
+	ctx := context.Background()
+	area := telemetry.Area{MinLat: 44, MinLng: -76, MaxLat: 46, MaxLng: -74}
+
+	page, err := svc.FindScooters(ctx, telemetry.Query{Area: area, Limit: telemetry.MaxPageSize * 10})
+	if err != nil {
+		t.Fatalf("FindScooters() error = %v", err)
+	}
+
+	if len(page.Scooters) != telemetry.MaxPageSize || page.Next == "" {
+		t.Errorf("expected a capped page of %d with a next cursor, got %d (next %q)", telemetry.MaxPageSize, len(page.Scooters), page.Next)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	qry := telemetry.Query{Area: area, Limit: 200}
+	pages := 0
+
+	for {
+		page, err := svc.FindScooters(ctx, qry)
+		if err != nil {
+			t.Fatalf("FindScooters() error = %v", err)
+		}
+		pages++
+
+		for _, s := range page.Scooters {
+			if seen[s.ID] {
+				t.Fatalf("scooter %s returned twice", s.ID)
+			}
+			seen[s.ID] = true
+		}
+
+		if page.Next == "" {
+			break
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/?minLat=44&minLng=-76&maxLat=46&maxLng=-74&limit=200&cursor="+page.Next, nil)
+		qry, err = telemetry.NewQuery(r)
+		if err != nil {
+			t.Fatalf("NewQuery() error = %v", err)
+		}
+	}
+
+	if pages != 3 || len(seen) != len(scooters) {
+		t.Errorf("expected %d scooters in 3 pages, got %d in %d", len(scooters), len(seen), pages)
+	}
+}
+
+func TestService_FleetManagement(t *testing.T) {
+	repo := mem.NewTelemetryRepo()
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+
+	_, err := svc.CreateScooter(rider, telemetry.Scooter{Lat: 45.42, Lng: -75.69, Battery: 80})
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("CreateScooter() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	_, err = svc.CreateScooter(operator, telemetry.Scooter{Status: telemetry.StatusOccupied, Lat: 45.42, Lng: -75.69})
+	if !errors.Is(err, telemetry.ErrInvalidStatus) {
+		t.Errorf("CreateScooter() occupied: expected ErrInvalidStatus, got %v", err)
+	}
+
+	low, err := svc.CreateScooter(operator, telemetry.Scooter{Lat: 45.42, Lng: -75.69, Battery: 5})
+	if err != nil {
+		t.Fatalf("CreateScooter() error = %v", err)
+	}
+
+	if low.ID == uuid.Nil || low.Status != telemetry.StatusLowBattery {
+		t.Errorf("expected generated ID and low_battery status, got %+v", low)
+	}
+
+	scooter, err := svc.CreateScooter(operator, telemetry.Scooter{Lat: 45.42, Lng: -75.69, Battery: 80})
+	if err != nil {
+		t.Fatalf("CreateScooter() error = %v", err)
+	}
+
+	_, err = svc.CreateScooter(operator, scooter)
+	if !errors.Is(err, telemetry.ErrAlreadyExists) {
+		t.Errorf("CreateScooter() duplicate: expected ErrAlreadyExists, got %v", err)
+	}
+
+	_, err = svc.ReportEvent(rider, telemetry.Event{ScooterID: scooter.ID, Type: telemetry.EventTripStart})
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	err = svc.DeleteScooter(operator, scooter.ID)
+	if !errors.Is(err, telemetry.ErrInvalidTransition) {
+		t.Errorf("DeleteScooter() in ride: expected ErrInvalidTransition, got %v", err)
+	}
+
+	_, err = svc.ReportEvent(rider, telemetry.Event{ScooterID: scooter.ID, Type: telemetry.EventTripEnd})
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	err = svc.DeleteScooter(rider, scooter.ID)
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("DeleteScooter() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := svc.DeleteScooter(operator, scooter.ID); err != nil {
+			t.Fatalf("DeleteScooter() attempt %d error = %v", i+1, err)
+		}
+	}
+
+	got, err := svc.GetScooter(operator, scooter.ID)
+	if err != nil || got.Status != telemetry.StatusDecommissioned {
+		t.Errorf("expected decommissioned scooter to be kept, got %+v (err: %v)", got, err)
+	}
+
+	area := telemetry.Area{MinLat: 45, MinLng: -76, MaxLat: 46, MaxLng: -75}
+	page, err := svc.FindScooters(rider, telemetry.Query{Area: area})
+	if listed := page.Scooters; err != nil || len(listed) != 1 || listed[0].ID != low.ID {
+		t.Errorf("expected only the active scooter to be listed, got %+v (err: %v)", page, err)
+	}
+
+	_, err = svc.ReportEvent(rider, telemetry.Event{ScooterID: scooter.ID, Type: telemetry.EventTripStart})
+	if !errors.Is(err, telemetry.ErrInvalidTransition) {
+		t.Errorf("ReportEvent() on decommissioned scooter: expected ErrInvalidTransition, got %v", err)
+	}
+}
+
+func TestService_UpdateScooter(t *testing.T) {
+	id := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID: id, Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80, Version: 1,
+	}))
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+
+	battery := 10
+	_, err := svc.UpdateScooter(rider, telemetry.ScooterPatch{ScooterID: id, Battery: &battery})
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("UpdateScooter() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	got, err := svc.UpdateScooter(operator, telemetry.ScooterPatch{ScooterID: id, Version: 1, Battery: &battery})
+	if err != nil {
+		t.Fatalf("UpdateScooter() error = %v", err)
+	}
+
+	if got.Battery != 10 || got.Status != telemetry.StatusLowBattery || got.Lat != 45.42 || got.Version != 2 {
+		t.Errorf("expected only battery and status to change at version 2, got %+v", got)
+	}
+
+	stored, _ := repo.GetScooter(context.Background(), id)
+	if stored != got {
+		t.Errorf("expected stored scooter %+v, got %+v", got, stored)
+	}
+
+	// A concurrent change moves the scooter to version 3.
+	_, err = svc.ReportEvent(rider, telemetry.Event{ScooterID: id, Type: telemetry.EventBattery, Battery: &battery})
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	lat := 45.43
+	_, err = svc.UpdateScooter(operator, telemetry.ScooterPatch{ScooterID: id, Version: 2, Lat: &lat})
+	if !errors.Is(err, telemetry.ErrVersionConflict) {
+		t.Errorf("UpdateScooter() stale: expected ErrVersionConflict, got %v", err)
+	}
+
+	got, err = svc.UpdateScooter(operator, telemetry.ScooterPatch{ScooterID: id, Lat: &lat})
+	if err != nil || got.Lat != lat || got.Version != 4 {
+		t.Errorf("UpdateScooter() without version: got %+v (err: %v)", got, err)
+	}
+
+	_, err = svc.UpdateScooter(operator, telemetry.ScooterPatch{ScooterID: uuid.New(), Lat: &lat})
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("UpdateScooter() unknown scooter: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestService_ImportScooters(t *testing.T) {
+	existingID, ridingID, retiredID, lowID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(
+		telemetry.Scooter{ID: existingID, Status: telemetry.StatusFree, Lat: 45, Lng: -75, Battery: 70},
+		telemetry.Scooter{ID: ridingID, Status: telemetry.StatusOccupied, Lat: 45, Lng: -75, Battery: 70},
+		telemetry.Scooter{ID: retiredID, Status: telemetry.StatusDecommissioned, Lat: 45, Lng: -75, Battery: 70},
+		telemetry.Scooter{ID: lowID, Status: telemetry.StatusLowBattery, Lat: 45, Lng: -75, Battery: 10},
+	))
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+
+	input := strings.Join([]string{
+		"id,status,lat,lng,battery",
+		existingID.String() + ",maintenance,45.1,-75.1,",
+		",,45.2,-75.2,90",
+		",,45.3,-75.3,5",
+		ridingID.String() + ",offline,45.4,-75.4,50",
+		",reserved,45.5,-75.5,50",
+		",,95.0,-75.6,50",
+		",,45.7,-75.7,",
+		retiredID.String() + ",free,45.8,-75.8,90",
+		lowID.String() + ",free,45.9,-75.9,",
+	}, "\n")
+
+	_, err := svc.ImportScooters(context.Background(), telemetry.FormatCSV, strings.NewReader(input))
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("ImportScooters() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	res, err := svc.ImportScooters(operator, telemetry.FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportScooters() error = %v", err)
+	}
+
+	if res.Created != 2 || res.Updated != 1 || res.Failed != 6 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	var failedRows []int
+	for _, re := range res.Errors {
+		failedRows = append(failedRows, re.Row)
+	}
+
+	if want := []int{5, 6, 7, 8, 9, 10}; !equalInts(failedRows, want) {
+		t.Errorf("failed rows = %v, want %v", failedRows, want)
+	}
+
+	updated, _ := svc.GetScooter(operator, existingID)
+	if updated.Status != telemetry.StatusMaintenance || updated.Lat != 45.1 || updated.Battery != 70 {
+		t.Errorf("expected status and location updated keeping battery, got %+v", updated)
+	}
+
+	riding, _ := svc.GetScooter(operator, ridingID)
+	if riding.Status != telemetry.StatusOccupied || riding.Lat != 45 {
+		t.Errorf("expected scooter in a ride to be left untouched, got %+v", riding)
+	}
+
+	retired, _ := svc.GetScooter(operator, retiredID)
+	if retired.Status != telemetry.StatusDecommissioned || retired.Lat != 45 {
+		t.Errorf("expected decommissioned scooter to be left untouched, got %+v", retired)
+	}
+
+	low, _ := svc.GetScooter(operator, lowID)
+	if low.Status != telemetry.StatusLowBattery || low.Lat != 45 {
+		t.Errorf("expected a status change operators cannot make to be rejected, got %+v", low)
+	}
+
+	var buf bytes.Buffer
+	err = svc.ExportScooters(operator, telemetry.FormatCSV, &buf)
+	if err != nil {
+		t.Fatalf("ExportScooters() error = %v", err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 7 {
+		t.Errorf("expected header and 6 scooters exported, got %d lines", lines)
+	}
+
+	if !strings.Contains(buf.String(), ",low_battery,45.3,-75.3,5,") {
+		t.Errorf("expected imported low battery scooter in export, got:\n%s", buf.String())
+	}
+}
+
+func TestService_ZoneViolations(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:        scooterID,
+		Status:    telemetry.StatusFree,
+		Lat:       45.0,
+		Lng:       -75.0,
+		Battery:   100,
+		UpdatedAt: time.Now(),
+	}))
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+
+	zone, err := svc.CreateZone(operator, telemetry.Zone{
+		Name:   "Parliament Hill",
+		Rule:   telemetry.RuleNoParking,
+		Active: true,
+		Polygon: []telemetry.Point{
+			{Lat: 45.01, Lng: -75.01},
+			{Lat: 45.01, Lng: -74.99},
+			{Lat: 45.03, Lng: -74.99},
+			{Lat: 45.03, Lng: -75.01},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateZone() error = %v", err)
+	}
+
+	_, err = svc.CreateZone(rider, zone)
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("CreateZone() by rider: expected ErrOperatorOnly, got %v", err)
+	}
+
+	events := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.02, Lng: -75.0},
+		{ScooterID: scooterID, Type: telemetry.EventTripEnd},
+	}
+
+	for _, e := range events {
+		if _, err := svc.ReportEvent(rider, e); err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+	}
+
+	violations, err := svc.FindViolations(telemetry.WithOperator(context.Background()), telemetry.ViolationQuery{ScooterID: scooterID})
+	if err != nil {
+		t.Fatalf("FindViolations() error = %v", err)
+	}
+
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d", len(violations))
+	}
+
+	v := violations[0]
+	if v.ZoneID != zone.ID || v.Rule != telemetry.RuleNoParking || v.TripID == nil {
+		t.Errorf("unexpected violation: %+v", v)
+	}
+
+	byTrip, err := svc.FindViolations(rider, telemetry.ViolationQuery{TripID: *v.TripID})
+	if err != nil || len(byTrip) != 1 {
+		t.Errorf("expected 1 violation for trip, got %d (err: %v)", len(byTrip), err)
+	}
+
+	_, err = svc.FindViolations(telemetry.WithClientID(context.Background(), "rider-2"), telemetry.ViolationQuery{TripID: *v.TripID})
+	if !errors.Is(err, telemetry.ErrNotFound) {
+		t.Errorf("FindViolations() by another rider: expected ErrNotFound, got %v", err)
+	}
+
+	_, err = svc.FindViolations(rider, telemetry.ViolationQuery{ScooterID: scooterID})
+	if !errors.Is(err, telemetry.ErrOperatorOnly) {
+		t.Errorf("FindViolations() of a scooter by rider: expected ErrOperatorOnly, got %v", err)
+	}
+}
+
+func TestService_ZoneViolationsSpeed(t *testing.T) {
+	scooterID := uuid.New()
+	repo := mem.NewTelemetryRepo(initialData(telemetry.Scooter{
+		ID:      scooterID,
+		Status:  telemetry.StatusFree,
+		Lat:     45.0,
+		Lng:     -75.0,
+		Battery: 100,
+	}))
+	svc := telemetry.NewService(repo)
+	operator := telemetry.WithOperator(context.Background())
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+
+	_, err := svc.CreateZone(operator, telemetry.Zone{
+		Name:     "Market",
+		Rule:     telemetry.RuleSlowZone,
+		MaxSpeed: 10,
+		Active:   true,
+		Polygon: []telemetry.Point{
+			{Lat: 44.99, Lng: -75.01},
+			{Lat: 44.99, Lng: -74.99},
+			{Lat: 45.01, Lng: -74.99},
+			{Lat: 45.01, Lng: -75.01},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateZone() error = %v", err)
+	}
+
+	// Roughly 100m per 0.0009 degree of latitude: a minute apart is 6 km/h,
+	// ten seconds apart 36 km/h.
+	start := time.Now().Add(-10 * time.Minute)
+	battery := 90
+	events := []telemetry.Event{
+		{ScooterID: scooterID, Type: telemetry.EventTripStart},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.0, Lng: -75.0, OccurredAt: start},
+		{ScooterID: scooterID, Type: telemetry.EventBattery, Battery: &battery, OccurredAt: start.Add(55 * time.Second)},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.0009, Lng: -75.0, OccurredAt: start.Add(time.Minute)},
+		{ScooterID: scooterID, Type: telemetry.EventLocation, Lat: 45.0018, Lng: -75.0, OccurredAt: start.Add(70 * time.Second)},
+	}
+
+	for _, e := range events {
+		if _, err := svc.ReportEvent(rider, e); err != nil {
+			t.Fatalf("ReportEvent(%s) error = %v", e.Type, err)
+		}
+	}
+
+	violations, err := svc.FindViolations(telemetry.WithOperator(context.Background()), telemetry.ViolationQuery{ScooterID: scooterID})
+	if err != nil {
+		t.Fatalf("FindViolations() error = %v", err)
+	}
+
+	if len(violations) != 1 || violations[0].Speed < 30 || violations[0].Speed > 40 {
+		t.Errorf("expected only the 36 km/h move to break the slow zone, got %+v", violations)
+	}
+}
+
+func initialData(scooters ...telemetry.Scooter) map[uuid.UUID]telemetry.Scooter {
+	data := make(map[uuid.UUID]telemetry.Scooter, len(scooters))
+	for _, s := range scooters {
+		data[s.ID] = s
+	}
+	return data
+}
+
+type txMark struct{}
+
+// txRepo records the writes made outside of a transaction and the outcome of
+// the transactions.
+type txRepo struct {
+	*mem.TelemetryRepo
+	outside    []string
+	rolledBack []error
+	failUpdate bool
+}
+
+func (r *txRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, txMark{}, true))
+	if err != nil {
+		r.rolledBack = append(r.rolledBack, err)
+	}
+	return err
+}
+
+func (r *txRepo) write(ctx context.Context, name string) {
+	if ctx.Value(txMark{}) == nil {
+		r.outside = append(r.outside, name)
+	}
+}
+
+func (r *txRepo) StoreEvent(ctx context.Context, e telemetry.Event) error {
+	r.write(ctx, "StoreEvent")
+	return r.TelemetryRepo.StoreEvent(ctx, e)
+}
+
+func (r *txRepo) CreateTrip(ctx context.Context, trip telemetry.Trip) error {
+	r.write(ctx, "CreateTrip")
+	return r.TelemetryRepo.CreateTrip(ctx, trip)
+}
+
+func (r *txRepo) UpdateScooter(ctx context.Context, s telemetry.Scooter) error {
+	r.write(ctx, "UpdateScooter")
+	if r.failUpdate {
+		return telemetry.ErrVersionConflict
+	}
+	return r.TelemetryRepo.UpdateScooter(ctx, s)
+}
+
+func TestService_ReportEventTx(t *testing.T) {
+	scooterID := uuid.New()
+	repo := &txRepo{TelemetryRepo: mem.NewTelemetryRepo(initialData(telemetry.Scooter{ID: scooterID, Status: telemetry.StatusFree, Battery: 100}))}
+	svc := telemetry.NewService(repo)
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	_, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripStart})
+	if err != nil {
+		t.Fatalf("ReportEvent() error = %v", err)
+	}
+
+	if len(repo.outside) != 0 {
+		t.Errorf("expected every write in the transaction, got %v outside", repo.outside)
+	}
+
+	repo.failUpdate = true
+	_, err = svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooterID, Type: telemetry.EventTripEnd})
+	if !errors.Is(err, telemetry.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	if len(repo.rolledBack) != 1 || !errors.Is(repo.rolledBack[0], telemetry.ErrVersionConflict) {
+		t.Errorf("expected the failed event to roll the transaction back, got %v", repo.rolledBack)
+	}
 }
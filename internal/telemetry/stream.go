@@ -0,0 +1,232 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// StreamHeartbeatInterval is how often an idle stream sends a comment to
+	// keep proxies from closing the connection.
+	StreamHeartbeatInterval = 15 * time.Second
+	// streamBacklog is how many recent changes are kept to resume streams
+	// from their Last-Event-ID.
+	streamBacklog = 1024
+	// streamBuffer is how many changes a subscriber may fall behind before it
+	// is dropped.
+	streamBuffer = 64
+	// streamRetry is the reconnection delay, in milliseconds, suggested to
+	// stream clients.
+	streamRetry = 2000
+)
+
+// StreamQuery selects the scooter changes to stream. LastEventID is the ID of
+// the last change the client received, if it is resuming a stream. ScooterID,
+// when set, limits the stream to that scooter wherever it is and the area is
+// ignored.
+type StreamQuery struct {
+	Area        Area
+	ScooterID   uuid.UUID
+	LastEventID string
+}
+
+// ScooterChange is a scooter state stored by the service. IDs order changes
+// within the running process.
+type ScooterChange struct {
+	ID      string
+	Scooter Scooter
+	seq     uint64
+	prev    *Point // location before the change, if known
+	origin  string // ID of the request that made the change
+}
+
+// matches reports whether the change is selected by qry: the scooter is the
+// one asked for or it is inside the area or has just left it.
+func (c ScooterChange) matches(qry StreamQuery) bool {
+	if qry.ScooterID != uuid.Nil {
+		return c.Scooter.ID == qry.ScooterID
+	}
+
+	if qry.Area.Contains(c.Scooter.Location()) {
+		return true
+	}
+
+	return c.prev != nil && qry.Area.Contains(*c.prev)
+}
+
+// changeFeed fans out scooter changes to the streams watching them. It only
+// lives in the process: changes made by other instances are not seen. Slow
+// subscribers never block the publisher, they are dropped instead and can
+// resume from the backlog.
+type changeFeed struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	backlog []ScooterChange
+	last    map[uuid.UUID]Point
+	subs    map[*Subscription]struct{}
+}
+
+func newChangeFeed() *changeFeed {
+	return &changeFeed{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		last:  make(map[uuid.UUID]Point),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// publish numbers the change of s made by the origin request and delivers it
+// to the matching subscribers.
+func (f *changeFeed) publish(origin string, s Scooter) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+	c := ScooterChange{ID: fmt.Sprintf("%s-%d", f.epoch, f.seq), Scooter: s, seq: f.seq, origin: origin}
+	if p, ok := f.last[s.ID]; ok {
+		c.prev = &p
+	}
+	f.last[s.ID] = s.Location()
+
+	f.backlog = append(f.backlog, c)
+	if len(f.backlog) > streamBacklog {
+		f.backlog = f.backlog[len(f.backlog)-streamBacklog:]
+	}
+
+	for sub := range f.subs {
+		if !c.matches(sub.qry) {
+			continue
+		}
+
+		select {
+		case sub.ch <- c:
+		default:
+			sub.lagged = true
+			f.drop(sub)
+		}
+	}
+}
+
+// subscribe starts delivering the changes selected by qry. Changes newer than
+// qry.LastEventID still in the backlog are delivered first.
+func (f *changeFeed) subscribe(qry StreamQuery) *Subscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replay []ScooterChange
+	var reset bool
+	if qry.LastEventID != "" {
+		replay, reset = f.since(qry.LastEventID)
+	}
+
+	sub := &Subscription{
+		qry:   qry,
+		ch:    make(chan ScooterChange, streamBuffer+len(replay)),
+		reset: reset,
+		feed:  f,
+	}
+
+	for _, c := range replay {
+		if c.matches(qry) {
+			sub.ch <- c
+		}
+	}
+
+	f.subs[sub] = struct{}{}
+	return sub
+}
+
+// since returns the backlog changes after the change with the given ID. Reset
+// is set when the changes in between are no longer known, e.g. the ID is from
+// a previous process or too old, and the client has to reload its state.
+func (f *changeFeed) since(id string) (changes []ScooterChange, reset bool) {
+	epoch, n, ok := strings.Cut(id, "-")
+	seq, err := strconv.ParseUint(n, 10, 64)
+	if !ok || err != nil || epoch != f.epoch || seq > f.seq {
+		return nil, true
+	}
+
+	if seq == f.seq {
+		return nil, false
+	}
+
+	if seq+1 < f.backlog[0].seq {
+		return nil, true
+	}
+
+	i := len(f.backlog) - int(f.seq-seq)
+	return f.backlog[i:], false
+}
+
+// drop removes sub from the feed and closes its channel. f.mu must be held.
+func (f *changeFeed) drop(sub *Subscription) {
+	if _, ok := f.subs[sub]; !ok {
+		return
+	}
+
+	delete(f.subs, sub)
+	close(sub.ch)
+}
+
+// Subscription delivers the changes of the scooters inside an area, including
+// those leaving it, or of a single scooter.
+type Subscription struct {
+	qry    StreamQuery
+	ch     chan ScooterChange
+	reset  bool
+	lagged bool
+	feed   *changeFeed
+}
+
+// Changes returns the changes channel. It is closed when the subscription is
+// closed or dropped for falling behind.
+func (s *Subscription) Changes() <-chan ScooterChange {
+	return s.ch
+}
+
+// Reset reports whether the stream could not be resumed from the requested
+// position and the client must reload the scooters before applying changes.
+func (s *Subscription) Reset() bool {
+	return s.reset
+}
+
+// Lagged reports whether the subscription was dropped because its reader did
+// not keep up. Only meaningful once Changes is closed.
+func (s *Subscription) Lagged() bool {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	return s.lagged
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.drop(s)
+}
+
+// writeSSE writes a Server-Sent Event carrying data as JSON. The ID is left
+// out when empty.
+func writeSSE(w io.Writer, id, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		_, err = fmt.Fprintf(w, "id: %s\n", id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
@@ -0,0 +1,155 @@
+package telemetry_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/repo/mem"
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+var ottawa = telemetry.Area{MinLat: 45.3, MinLng: -75.8, MaxLat: 45.5, MaxLng: -75.6}
+
+func TestStreamScootersHandler(t *testing.T) {
+	inside := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	outside := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.50, Lng: -73.56, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(inside, outside)))
+	srv := httptest.NewServer(telemetry.NewRouter(telemetry.NewHandler(svc), []string{"key"}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	url := srv.URL + "/api/v1/scooters/stream?minLat=45.3&minLng=-75.8&maxLat=45.5&maxLng=-75.6"
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req.Header.Set("X-API-Key", "key")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	if !lines.Scan() || !strings.HasPrefix(lines.Text(), "retry:") {
+		t.Fatalf("expected the stream to open with a retry hint, got %q", lines.Text())
+	}
+
+	battery := 70
+	rider := telemetry.WithClientID(context.Background(), "rider-1")
+	for _, id := range []uuid.UUID{outside.ID, inside.ID} {
+		_, err = svc.ReportEvent(rider, telemetry.Event{ScooterID: id, Type: telemetry.EventBattery, Battery: &battery})
+		if err != nil {
+			t.Fatalf("ReportEvent() error = %v", err)
+		}
+	}
+
+	var event []string
+	for lines.Scan() {
+		if lines.Text() == "" {
+			if len(event) > 0 {
+				break
+			}
+			continue
+		}
+		event = append(event, lines.Text())
+	}
+
+	if len(event) != 3 || !strings.HasPrefix(event[0], "id: ") || event[1] != "event: scooter" {
+		t.Fatalf("expected a scooter event with an ID, got %q", event)
+	}
+
+	if !strings.Contains(event[2], inside.ID.String()) || !strings.Contains(event[2], `"battery":70`) {
+		t.Errorf("expected the change of the scooter inside the area, got %q", event[2])
+	}
+}
+
+func TestStreamScootersInvalidArea(t *testing.T) {
+	svc := telemetry.NewService(mem.NewTelemetryRepo())
+	h := telemetry.NewHandler(svc)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/scooters/stream?minLat=46&minLng=-75.8&maxLat=45&maxLng=-75.6", nil)
+	w := httptest.NewRecorder()
+
+	h.StreamScooters(w, r)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"area"`) {
+		t.Errorf("expected 400 on area, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestService_WatchScooters(t *testing.T) {
+	scooter := telemetry.Scooter{ID: uuid.New(), Status: telemetry.StatusFree, Lat: 45.42, Lng: -75.69, Battery: 80}
+	svc := telemetry.NewService(mem.NewTelemetryRepo(initialData(scooter)))
+	ctx := telemetry.WithClientID(context.Background(), "rider-1")
+
+	report := func(battery int) {
+		t.Helper()
+		_, err := svc.ReportEvent(ctx, telemetry.Event{ScooterID: scooter.ID, Type: telemetry.EventBattery, Battery: &battery})
+		if err != nil {
+			t.Fatalf("ReportEvent() error = %v", err)
+		}
+	}
+
+	live, err := svc.WatchScooters(ctx, telemetry.StreamQuery{Area: ottawa})
+	if err != nil {
+		t.Fatalf("WatchScooters() error = %v", err)
+	}
+	defer live.Close()
+
+	for _, b := range []int{70, 60, 50} {
+		report(b)
+	}
+
+	var ids []string
+	for range 3 {
+		c := <-live.Changes()
+		ids = append(ids, c.ID)
+	}
+
+	resumed, err := svc.WatchScooters(ctx, telemetry.StreamQuery{Area: ottawa, LastEventID: ids[0]})
+	if err != nil {
+		t.Fatalf("WatchScooters() error = %v", err)
+	}
+	defer resumed.Close()
+
+	for i, want := range []int{60, 50} {
+		c := <-resumed.Changes()
+		if c.ID != ids[i+1] || c.Scooter.Battery != want {
+			t.Errorf("resumed change %d = %s battery %d, want %s battery %d", i, c.ID, c.Scooter.Battery, ids[i+1], want)
+		}
+	}
+
+	if resumed.Reset() {
+		t.Errorf("expected a stream resumed within the backlog not to be reset")
+	}
+
+	unknown, _ := svc.WatchScooters(ctx, telemetry.StreamQuery{Area: ottawa, LastEventID: "gone-1"})
+	defer unknown.Close()
+	if !unknown.Reset() {
+		t.Errorf("expected a reset when resuming from an unknown ID")
+	}
+
+	// live is not read anymore: it must be dropped instead of blocking the
+	// reporters once its buffer is full.
+	for i := range 100 {
+		report(i)
+	}
+
+	for range live.Changes() {
+	}
+
+	if !live.Lagged() {
+		t.Errorf("expected the slow subscription to be dropped as lagged")
+	}
+}
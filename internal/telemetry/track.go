@@ -0,0 +1,263 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrackFormat is a scooter track export format.
+type TrackFormat string
+
+const (
+	TrackGeoJSON  TrackFormat = "geojson"
+	TrackGPX      TrackFormat = "gpx"
+	TrackPolyline TrackFormat = "polyline"
+)
+
+const (
+	// MaxTrackPoints caps the number of location events a track is built
+	// from; longer tracks keep the latest ones and are marked truncated.
+	MaxTrackPoints = 10000
+	// MaxTrackTolerance caps the simplification tolerance in meters.
+	MaxTrackTolerance = 1000
+)
+
+// TrackQuery selects the location events of a scooter between From
+// (inclusive) and To (exclusive). A positive Tolerance simplifies the track,
+// dropping the points closer than that many meters to the simplified path.
+type TrackQuery struct {
+	ScooterID uuid.UUID
+	From      time.Time
+	To        time.Time
+	Tolerance float64
+}
+
+// TrackPoint is a position of a scooter at the device time it was reported.
+type TrackPoint struct {
+	Point
+	At time.Time
+}
+
+// Track is the path of a scooter, in chronological order. Truncated is set
+// when earlier points were left out for exceeding MaxTrackPoints.
+type Track struct {
+	ScooterID uuid.UUID
+	Points    []TrackPoint
+	Truncated bool
+}
+
+// Simplify reduces the track with the Douglas-Peucker algorithm, keeping the
+// points farther than tolerance meters from the simplified path. The first
+// and last points are always kept.
+func Simplify(points []TrackPoint, tolerance float64) []TrackPoint {
+	if tolerance <= 0 || len(points) < 3 {
+		return points
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	markFarthest(points, keep, 0, len(points)-1, tolerance)
+
+	simplified := make([]TrackPoint, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+
+	return simplified
+}
+
+// markFarthest keeps the point between first and last farthest from the
+// segment joining them, if beyond tolerance, and recurses on both halves.
+func markFarthest(points []TrackPoint, keep []bool, first, last int, tolerance float64) {
+	farthest, maxDist := 0, tolerance
+	for i := first + 1; i < last; i++ {
+		d := segmentDistance(points[i].Point, points[first].Point, points[last].Point)
+		if d > maxDist {
+			farthest, maxDist = i, d
+		}
+	}
+
+	if farthest == 0 {
+		return
+	}
+
+	keep[farthest] = true
+	markFarthest(points, keep, first, farthest, tolerance)
+	markFarthest(points, keep, farthest, last, tolerance)
+}
+
+// segmentDistance returns the distance in meters from p to the segment ab, on
+// an equirectangular projection around a. Good enough at city scale.
+func segmentDistance(p, a, b Point) float64 {
+	scale := earthRadius * math.Pi / 180
+	cos := math.Cos(a.Lat * math.Pi / 180)
+	project := func(q Point) (float64, float64) {
+		return (q.Lng - a.Lng) * cos * scale, (q.Lat - a.Lat) * scale
+	}
+
+	px, py := project(p)
+	bx, by := project(b)
+
+	t := 0.0
+	if l := bx*bx + by*by; l > 0 {
+		t = math.Max(0, math.Min(1, (px*bx+py*by)/l))
+	}
+
+	return math.Hypot(px-t*bx, py-t*by)
+}
+
+// EncodePolyline encodes the points with the Google encoded polyline
+// algorithm, at 5 decimal places.
+func EncodePolyline(points []TrackPoint) string {
+	var sb strings.Builder
+	var prevLat, prevLng int64
+
+	for _, p := range points {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lng := int64(math.Round(p.Lng * 1e5))
+		encodePolylineValue(&sb, lat-prevLat)
+		encodePolylineValue(&sb, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+
+	return sb.String()
+}
+
+func encodePolylineValue(sb *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
+
+// WriteTrack writes the track to w in the given format.
+func WriteTrack(format TrackFormat, w io.Writer, track Track) error {
+	switch format {
+	case TrackGeoJSON:
+		return json.NewEncoder(w).Encode(newTrackFeature(track))
+	case TrackGPX:
+		return writeTrackGPX(w, track)
+	case TrackPolyline:
+		return json.NewEncoder(w).Encode(trackPolyline{
+			ScooterID: track.ScooterID,
+			Points:    len(track.Points),
+			Polyline:  EncodePolyline(track.Points),
+			Truncated: track.Truncated,
+		})
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+}
+
+// lineStringGeometry is a GeoJSON LineString. Coordinates are [lng, lat].
+type lineStringGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+type trackProperties struct {
+	ScooterID uuid.UUID   `json:"scooterId"`
+	Times     []time.Time `json:"times"`
+	Truncated bool        `json:"truncated"`
+}
+
+// trackFeature is a track as a GeoJSON LineString feature. The device times
+// of the positions are in the times property. A LineString needs two
+// positions, so shorter tracks have no geometry.
+type trackFeature struct {
+	Type       string              `json:"type"`
+	Geometry   *lineStringGeometry `json:"geometry"`
+	Properties trackProperties     `json:"properties"`
+}
+
+func newTrackFeature(track Track) trackFeature {
+	f := trackFeature{
+		Type: geoJSONFeature,
+		Properties: trackProperties{
+			ScooterID: track.ScooterID,
+			Times:     make([]time.Time, 0, len(track.Points)),
+			Truncated: track.Truncated,
+		},
+	}
+
+	if len(track.Points) < 2 {
+		return f
+	}
+
+	f.Geometry = &lineStringGeometry{Type: geoJSONLineString, Coordinates: make([][]float64, 0, len(track.Points))}
+	for _, p := range track.Points {
+		f.Geometry.Coordinates = append(f.Geometry.Coordinates, []float64{p.Lng, p.Lat})
+		f.Properties.Times = append(f.Properties.Times, p.At)
+	}
+
+	return f
+}
+
+// trackPolyline is a track as a Google encoded polyline.
+type trackPolyline struct {
+	ScooterID uuid.UUID `json:"scooterId"`
+	Points    int       `json:"points"`
+	Polyline  string    `json:"polyline"`
+	Truncated bool      `json:"truncated"`
+}
+
+// gpx is a GPX 1.1 document with a single track.
+type gpx struct {
+	XMLName xml.Name `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version string   `xml:"version,attr"`
+	Creator string   `xml:"creator,attr"`
+	Track   gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name    string       `xml:"name"`
+	Segment []gpxTrackPt `xml:"trkseg>trkpt"`
+}
+
+type gpxTrackPt struct {
+	Lat  float64   `xml:"lat,attr"`
+	Lon  float64   `xml:"lon,attr"`
+	Time time.Time `xml:"time"`
+}
+
+func writeTrackGPX(w io.Writer, track Track) error {
+	doc := gpx{
+		Version: "1.1",
+		Creator: "rida",
+		Track:   gpxTrack{Name: track.ScooterID.String()},
+	}
+
+	for _, p := range track.Points {
+		doc.Track.Segment = append(doc.Track.Segment, gpxTrackPt{Lat: p.Lat, Lon: p.Lng, Time: p.At.UTC()})
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
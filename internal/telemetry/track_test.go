@@ -0,0 +1,126 @@
+package telemetry_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func trackPoints(coords ...[2]float64) []telemetry.TrackPoint {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	points := make([]telemetry.TrackPoint, 0, len(coords))
+	for i, c := range coords {
+		points = append(points, telemetry.TrackPoint{
+			Point: telemetry.Point{Lat: c[0], Lng: c[1]},
+			At:    start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	return points
+}
+
+func TestSimplify(t *testing.T) {
+	// A straight northbound street with a ~1 m wobble and a ~110 m detour east.
+	points := trackPoints(
+		[2]float64{45.000, -75.0},
+		[2]float64{45.001, -75.00001},
+		[2]float64{45.002, -75.0},
+		[2]float64{45.003, -74.9986},
+		[2]float64{45.004, -75.0},
+	)
+
+	tests := []struct {
+		name      string
+		tolerance float64
+		want      int
+	}{
+		{"no tolerance", 0, 5},
+		{"drops the wobble", 10, 4},
+		{"drops the detour", 200, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := telemetry.Simplify(points, tt.tolerance)
+			if len(got) != tt.want {
+				t.Fatalf("Simplify() kept %d points, want %d", len(got), tt.want)
+			}
+
+			if got[0] != points[0] || got[len(got)-1] != points[len(points)-1] {
+				t.Errorf("expected the first and last points to be kept, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestEncodePolyline(t *testing.T) {
+	// Example of the Google encoded polyline algorithm documentation.
+	points := trackPoints(
+		[2]float64{38.5, -120.2},
+		[2]float64{40.7, -120.95},
+		[2]float64{43.252, -126.453},
+	)
+
+	want := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	if got := telemetry.EncodePolyline(points); got != want {
+		t.Errorf("EncodePolyline() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteTrack(t *testing.T) {
+	track := telemetry.Track{
+		ScooterID: uuid.New(),
+		Points:    trackPoints([2]float64{45.0, -75.0}, [2]float64{45.001, -75.002}),
+	}
+
+	var buf bytes.Buffer
+	err := telemetry.WriteTrack(telemetry.TrackGeoJSON, &buf, track)
+	if err != nil {
+		t.Fatalf("WriteTrack(geojson) error = %v", err)
+	}
+
+	var feature struct {
+		Geometry struct {
+			Type        string      `json:"type"`
+			Coordinates [][]float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			Times []time.Time `json:"times"`
+		} `json:"properties"`
+	}
+	_ = json.Unmarshal(buf.Bytes(), &feature)
+
+	if feature.Geometry.Type != "LineString" || len(feature.Geometry.Coordinates) != 2 ||
+		feature.Geometry.Coordinates[1][0] != -75.002 || len(feature.Properties.Times) != 2 {
+		t.Errorf("unexpected GeoJSON track: %s", buf.String())
+	}
+
+	buf.Reset()
+	err = telemetry.WriteTrack(telemetry.TrackGPX, &buf, track)
+	if err != nil {
+		t.Fatalf("WriteTrack(gpx) error = %v", err)
+	}
+
+	var doc struct {
+		Points []struct {
+			Lat  float64   `xml:"lat,attr"`
+			Lon  float64   `xml:"lon,attr"`
+			Time time.Time `xml:"time"`
+		} `xml:"trk>trkseg>trkpt"`
+	}
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	if err != nil || len(doc.Points) != 2 || doc.Points[1].Lon != -75.002 || !doc.Points[1].Time.Equal(track.Points[1].At) {
+		t.Errorf("unexpected GPX track (%v): %s", err, buf.String())
+	}
+
+	buf.Reset()
+	err = telemetry.WriteTrack(telemetry.TrackGeoJSON, &buf, telemetry.Track{ScooterID: track.ScooterID})
+	if err != nil || !bytes.Contains(buf.Bytes(), []byte(`"geometry":null`)) {
+		t.Errorf("expected an empty track without geometry, got %s, %v", buf.String(), err)
+	}
+}
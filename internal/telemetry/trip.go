@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingClientID = newError(KindInvalid, "missing_client_id", "missing client id")
+	ErrNotRideOwner    = newError(KindForbidden, "not_ride_owner", "ride belongs to another client")
+)
+
+// Trip is a single ride: it is opened by a trip_start event, extended by every
+// location event and closed by the matching trip_end.
+type Trip struct {
+	ID        uuid.UUID  `json:"id"`
+	ScooterID uuid.UUID  `json:"scooterId"`
+	ClientID  string     `json:"clientId"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Start     Point      `json:"start"`
+	End       *Point     `json:"end,omitempty"`
+	Distance  float64    `json:"distance"` // meters
+	Path      []Point    `json:"path"`
+	Fare      *Fare      `json:"fare,omitempty"`
+}
+
+func (t *Trip) GenID() {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+}
+
+// GenCreateVals sets the ID and opens the trip at the given time, seeding the
+// path with the start point.
+func (t *Trip) GenCreateVals(at time.Time) {
+	t.GenID()
+	t.StartedAt = at
+	t.Path = []Point{t.Start}
+}
+
+// Active reports whether the trip has not been closed yet.
+func (t *Trip) Active() bool {
+	return t.EndedAt == nil
+}
+
+// Extend appends a new position to the path and accumulates the distance
+// travelled since the previous one.
+func (t *Trip) Extend(p Point) {
+	if len(t.Path) > 0 {
+		t.Distance += Distance(t.Path[len(t.Path)-1], p)
+	}
+
+	t.Path = append(t.Path, p)
+}
+
+// Close ends the trip at the given point and time. A trip never ends before
+// it started.
+func (t *Trip) Close(p Point, at time.Time) {
+	if len(t.Path) == 0 || t.Path[len(t.Path)-1] != p {
+		t.Extend(p)
+	}
+
+	if at.Before(t.StartedAt) {
+		at = t.StartedAt
+	}
+
+	t.End = &p
+	t.EndedAt = &at
+}
+
+type TripQuery struct {
+	ScooterID uuid.UUID
+	ClientID  string
+	From      time.Time
+	To        time.Time
+}
+
+// Match reports whether the trip satisfies the query filters. Zero-valued
+// filters are ignored; From is inclusive and To exclusive on the start time.
+func (q TripQuery) Match(t Trip) bool {
+	if q.ScooterID != uuid.Nil && t.ScooterID != q.ScooterID {
+		return false
+	}
+
+	if q.ClientID != "" && t.ClientID != q.ClientID {
+		return false
+	}
+
+	if !q.From.IsZero() && t.StartedAt.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && !t.StartedAt.Before(q.To) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,98 @@
+package telemetry_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+	"github.com/google/uuid"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b telemetry.Point
+		want float64
+	}{
+		{"same point", telemetry.Point{Lat: 45, Lng: -75}, telemetry.Point{Lat: 45, Lng: -75}, 0},
+		{"one degree of latitude", telemetry.Point{Lat: 45, Lng: -75}, telemetry.Point{Lat: 46, Lng: -75}, 111195},
+		{"ottawa to montreal", telemetry.Point{Lat: 45.4215, Lng: -75.6972}, telemetry.Point{Lat: 45.5017, Lng: -73.5673}, 166347},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := telemetry.Distance(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 100 {
+				t.Errorf("Distance() = %v, want ~%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTripLifecycle(t *testing.T) {
+	start := telemetry.Point{Lat: 45.0, Lng: -75.0}
+	trip := telemetry.Trip{ScooterID: uuid.New(), Start: start}
+	trip.GenCreateVals(time.Now())
+
+	if trip.ID == uuid.Nil || !trip.Active() || len(trip.Path) != 1 {
+		t.Fatalf("unexpected new trip: %+v", trip)
+	}
+
+	trip.Extend(telemetry.Point{Lat: 45.001, Lng: -75.0})
+	end := telemetry.Point{Lat: 45.002, Lng: -75.0}
+	trip.Close(end, time.Now())
+
+	if trip.Active() {
+		t.Errorf("expected trip to be closed")
+	}
+
+	if len(trip.Path) != 3 {
+		t.Errorf("expected 3 path points, got %d", len(trip.Path))
+	}
+
+	want := telemetry.Distance(start, end)
+	if math.Abs(trip.Distance-want) > 0.01 {
+		t.Errorf("trip distance = %v, want %v", trip.Distance, want)
+	}
+}
+
+func TestTripCloseBeforeStart(t *testing.T) {
+	start := time.Now()
+	trip := telemetry.Trip{ScooterID: uuid.New()}
+	trip.GenCreateVals(start)
+
+	trip.Close(telemetry.Point{Lat: 45.0, Lng: -75.0}, start.Add(-48*time.Hour))
+
+	if trip.EndedAt == nil || !trip.EndedAt.Equal(start) {
+		t.Errorf("expected the trip to end when it started, got %v", trip.EndedAt)
+	}
+}
+
+func TestTripQueryMatch(t *testing.T) {
+	scooterID := uuid.New()
+	now := time.Now()
+	trip := telemetry.Trip{ScooterID: scooterID, ClientID: "rider-1", StartedAt: now}
+
+	tests := []struct {
+		name string
+		qry  telemetry.TripQuery
+		want bool
+	}{
+		{"empty query", telemetry.TripQuery{}, true},
+		{"matching scooter and client", telemetry.TripQuery{ScooterID: scooterID, ClientID: "rider-1"}, true},
+		{"other scooter", telemetry.TripQuery{ScooterID: uuid.New()}, false},
+		{"other client", telemetry.TripQuery{ClientID: "rider-2"}, false},
+		{"within range", telemetry.TripQuery{From: now.Add(-time.Hour), To: now.Add(time.Hour)}, true},
+		{"from is inclusive", telemetry.TripQuery{From: now}, true},
+		{"to is exclusive", telemetry.TripQuery{To: now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.qry.Match(trip); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
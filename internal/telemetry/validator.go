@@ -1,7 +1,9 @@
 package telemetry
 
 import (
-	"errors"
+	"fmt"
+	"math"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -10,14 +12,35 @@ type ValidationOp string
 
 const (
 	OpGetScooter    ValidationOp = "get"
+	OpCreateScooter ValidationOp = "create"
+	OpImportScooter ValidationOp = "import"
 	OpUpdateScooter ValidationOp = "update"
 	OpFindScooters  ValidationOp = "find"
+	OpFindNearby    ValidationOp = "find_nearby"
+	OpScooterGrid   ValidationOp = "scooter_grid"
+	OpWatchScooters ValidationOp = "watch_scooters"
 	OpReportEvent   ValidationOp = "report_event"
+	OpGetTrip       ValidationOp = "get_trip"
+	OpFindTrips     ValidationOp = "find_trips"
+	OpFindEvents    ValidationOp = "find_events"
+	OpTrack         ValidationOp = "track"
+	OpChangeStatus  ValidationOp = "change_status"
+	OpSaveZone      ValidationOp = "save_zone"
+	OpGetZone       ValidationOp = "get_zone"
 )
 
 type Validator func(op ValidationOp, data interface{}) error
 
-var ErrInvalidID = errors.New("invalid scooter id")
+var (
+	ErrInvalidID      = newError(KindInvalid, "invalid_scooter_id", "invalid scooter id")
+	ErrInvalidScooter = newError(KindInvalid, "invalid_scooter", "invalid scooter")
+	ErrInvalidTripID  = newError(KindInvalid, "invalid_trip_id", "invalid trip id")
+	ErrInvalidStatus  = newError(KindInvalid, "invalid_status", "invalid status")
+	ErrInvalidZoneID  = newError(KindInvalid, "invalid_zone_id", "invalid zone id")
+	ErrInvalidZone    = newError(KindInvalid, "invalid_zone", "invalid zone")
+	ErrInvalidQuery   = newError(KindInvalid, "invalid_query", "invalid query")
+	ErrInvalidEvent   = newError(KindInvalid, "invalid_event", "invalid event")
+)
 
 func DefaultValidator(op ValidationOp, data interface{}) error {
 	switch op {
@@ -27,25 +50,174 @@ func DefaultValidator(op ValidationOp, data interface{}) error {
 			return ErrInvalidID
 		}
 
+	case OpCreateScooter:
+		// New scooters join the fleet idle: free, or out of circulation until
+		// they are ready.
+		return validateScooter(data, StatusFree, StatusMaintenance, StatusOffline)
+
+	case OpImportScooter:
+		// Imports sync the inventory, they may also retire scooters. Scooters
+		// in use are rejected by the service.
+		return validateScooter(data, StatusFree, StatusMaintenance, StatusOffline,
+			StatusLowBattery, StatusDecommissioned)
+
 	case OpUpdateScooter:
-		scooter, ok := data.(Scooter)
-		if !ok || scooter.ID == uuid.Nil {
-			return ErrInvalidID
-		}
+		return validateScooterPatch(data)
 
 	case OpFindScooters:
 		params, ok := data.(Query)
 		if !ok {
-			return errors.New("invalid query params")
+			return ErrInvalidQuery
+		}
+
+		if err := validateArea(params.Area); err != nil {
+			return err
+		}
+
+		if params.Status != "" && !IsValidStatus(params.Status) {
+			return invalidStatus(params.Status)
+		}
+
+		if !isValidBattery(params.MinBattery) {
+			return invalidField(ErrInvalidQuery, "minBattery", "invalid min battery")
+		}
+
+		if params.Limit < 0 {
+			return invalidField(ErrInvalidQuery, "limit", "invalid limit")
+		}
+
+	case OpScooterGrid:
+		qry, ok := data.(GridQuery)
+		if !ok {
+			return ErrInvalidQuery
+		}
+
+		if err := validateArea(qry.Area); err != nil {
+			return err
+		}
+
+		if !(qry.CellSize > 0) || math.IsInf(qry.CellSize, 1) {
+			return invalidField(ErrInvalidQuery, "cellSize", "cell size must be positive")
+		}
+
+		if rows, cols := qry.span(); rows*cols > MaxGridCells {
+			return invalidField(ErrInvalidQuery, "cellSize",
+				fmt.Sprintf("grid would have more than %d cells", MaxGridCells))
+		}
+
+		if qry.Status != "" && !IsValidStatus(qry.Status) {
+			return invalidStatus(qry.Status)
+		}
+
+	case OpWatchScooters:
+		qry, ok := data.(StreamQuery)
+		if !ok {
+			return ErrInvalidQuery
+		}
+
+		if qry.ScooterID == uuid.Nil {
+			return validateArea(qry.Area)
+		}
+
+	case OpFindNearby:
+		qry, ok := data.(NearbyQuery)
+		if !ok {
+			return ErrInvalidQuery
+		}
+
+		if err := validatePoint(ErrInvalidQuery, qry.Center); err != nil {
+			return err
+		}
+
+		if qry.Radius <= 0 || qry.Radius > MaxNearbyRadius {
+			return invalidField(ErrInvalidQuery, "radius",
+				fmt.Sprintf("radius must be between 0 and %d meters", MaxNearbyRadius))
 		}
 
-		if params.Area.MinLat > params.Area.MaxLat ||
-			params.Area.MinLng > params.Area.MaxLng {
-			return errors.New("invalid area bounds")
+		if qry.Limit < 1 || qry.Limit > MaxNearbyLimit {
+			return invalidField(ErrInvalidQuery, "limit",
+				fmt.Sprintf("limit must be between 1 and %d", MaxNearbyLimit))
+		}
+
+		if qry.Status != "" && !IsValidStatus(qry.Status) {
+			return invalidStatus(qry.Status)
+		}
+
+		if !isValidBattery(qry.MinBattery) {
+			return invalidField(ErrInvalidQuery, "minBattery", "invalid min battery")
 		}
 
 	case OpReportEvent:
 		return validateReportEvent(data)
+
+	case OpChangeStatus:
+		change, ok := data.(StatusChange)
+		if !ok || change.ScooterID == uuid.Nil {
+			return ErrInvalidID
+		}
+
+		if !IsValidStatus(change.Status) {
+			return invalidStatus(change.Status)
+		}
+
+	case OpSaveZone:
+		return validateZone(data)
+
+	case OpGetZone:
+		id, ok := data.(uuid.UUID)
+		if !ok || id == uuid.Nil {
+			return ErrInvalidZoneID
+		}
+
+	case OpGetTrip:
+		id, ok := data.(uuid.UUID)
+		if !ok || id == uuid.Nil {
+			return ErrInvalidTripID
+		}
+
+	case OpFindEvents:
+		qry, ok := data.(EventQuery)
+		if !ok || qry.ScooterID == uuid.Nil {
+			return ErrInvalidID
+		}
+
+		if qry.Type != "" && !IsValidEventType(qry.Type) {
+			return invalidField(ErrInvalidQuery, "type", "invalid event type")
+		}
+
+		if !qry.From.IsZero() && !qry.To.IsZero() && qry.To.Before(qry.From) {
+			return invalidField(ErrInvalidQuery, "to", "invalid time range")
+		}
+
+		if qry.Limit < 1 || qry.Limit > MaxEventLimit {
+			return invalidField(ErrInvalidQuery, "limit",
+				fmt.Sprintf("limit must be between 1 and %d", MaxEventLimit))
+		}
+
+	case OpTrack:
+		qry, ok := data.(TrackQuery)
+		if !ok || qry.ScooterID == uuid.Nil {
+			return ErrInvalidID
+		}
+
+		if !qry.From.IsZero() && !qry.To.IsZero() && qry.To.Before(qry.From) {
+			return invalidField(ErrInvalidQuery, "to", "invalid time range")
+		}
+
+		if qry.Tolerance < 0 || qry.Tolerance > MaxTrackTolerance {
+			return invalidField(ErrInvalidQuery, "tolerance",
+				fmt.Sprintf("tolerance must be between 0 and %d meters", MaxTrackTolerance))
+		}
+
+	case OpFindTrips:
+		qry, ok := data.(TripQuery)
+		if !ok {
+			return ErrInvalidQuery
+		}
+
+		if !qry.From.IsZero() && !qry.To.IsZero() && qry.To.Before(qry.From) {
+			return invalidField(ErrInvalidQuery, "to", "invalid time range")
+		}
 	}
 
 	return nil
@@ -55,26 +227,157 @@ func DefaultValidator(op ValidationOp, data interface{}) error {
 func validateReportEvent(v interface{}) error {
 	e, ok := v.(Event)
 	if !ok {
-		return errors.New("invalid event type")
+		return ErrInvalidEvent
 	}
 
 	if e.ScooterID == uuid.Nil {
-		return errors.New("invalid scooter id")
+		return invalidField(ErrInvalidID, "scooterId", "missing scooter id")
 	}
 
 	if !IsValidEventType(e.Type) {
-		return errors.New("invalid event type")
+		return invalidField(ErrInvalidEvent, "type", "invalid event type")
+	}
+
+	if e.Type == EventBattery && (e.Battery == nil || !isValidBattery(*e.Battery)) {
+		return invalidField(ErrInvalidEvent, "battery", "invalid battery level")
+	}
+
+	if e.Type == EventLocation {
+		return validatePoint(ErrInvalidEvent, Point{Lat: e.Lat, Lng: e.Lng})
+	}
+
+	return nil
+}
+
+// validateScooter validates a scooter being registered or imported, which
+// may only be in one of the allowed statuses.
+func validateScooter(v interface{}, allowed ...Status) error {
+	s, ok := v.(Scooter)
+	if !ok {
+		return ErrInvalidScooter
+	}
+
+	if !slices.Contains(allowed, s.Status) {
+		return invalidField(ErrInvalidStatus, "status", fmt.Sprintf("scooter cannot be registered as %q", s.Status))
+	}
+
+	if err := validatePoint(ErrInvalidScooter, s.Location()); err != nil {
+		return err
+	}
+
+	if !isValidBattery(s.Battery) {
+		return invalidField(ErrInvalidScooter, "battery", "invalid battery level")
+	}
+
+	return nil
+}
+
+// validateScooterPatch validates a partial scooter update, which must change
+// at least one field.
+func validateScooterPatch(v interface{}) error {
+	p, ok := v.(ScooterPatch)
+	if !ok || p.ScooterID == uuid.Nil {
+		return ErrInvalidID
+	}
+
+	if p.Lat == nil && p.Lng == nil && p.Battery == nil {
+		return fmt.Errorf("%w: nothing to update", ErrInvalidScooter)
+	}
+
+	if p.Lat != nil && (*p.Lat < -90 || *p.Lat > 90) {
+		return invalidField(ErrInvalidScooter, "lat", "invalid coordinates")
+	}
+
+	if p.Lng != nil && (*p.Lng < -180 || *p.Lng > 180) {
+		return invalidField(ErrInvalidScooter, "lng", "invalid coordinates")
+	}
+
+	if p.Battery != nil && !isValidBattery(*p.Battery) {
+		return invalidField(ErrInvalidScooter, "battery", "invalid battery level")
+	}
+
+	return nil
+}
+
+func validateZone(v interface{}) error {
+	z, ok := v.(Zone)
+	if !ok {
+		return ErrInvalidZone
+	}
+
+	if z.Name == "" {
+		return invalidField(ErrInvalidZone, "name", "missing name")
+	}
+
+	if !IsValidZoneRule(z.Rule) {
+		return invalidField(ErrInvalidZone, "rule", fmt.Sprintf("unknown rule %q", z.Rule))
+	}
+
+	if z.MaxSpeed < 0 {
+		return invalidField(ErrInvalidZone, "maxSpeed", "negative max speed")
+	}
+
+	ring := z.Polygon
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+
+	if len(ring) < 3 {
+		return invalidField(ErrInvalidZone, "polygon", "polygon needs at least 3 vertices")
+	}
+
+	for _, p := range ring {
+		if !IsValidPoint(p) {
+			return invalidField(ErrInvalidZone, "polygon", fmt.Sprintf("invalid coordinates (%v, %v)", p.Lat, p.Lng))
+		}
 	}
 
 	return nil
 }
 
+// validatePoint reports out of range coordinates as a field error of err.
+func validatePoint(err error, p Point) error {
+	// Negated so that NaN, which fails every comparison, is rejected too.
+	if !(p.Lat >= -90 && p.Lat <= 90) {
+		return invalidField(err, "lat", "invalid coordinates")
+	}
+
+	if !(p.Lng >= -180 && p.Lng <= 180) {
+		return invalidField(err, "lng", "invalid coordinates")
+	}
+
+	return nil
+}
+
+// validateArea rejects areas whose bounds are reversed.
+func validateArea(a Area) error {
+	if a.MinLat > a.MaxLat || a.MinLng > a.MaxLng {
+		return invalidField(ErrInvalidQuery, "area", "invalid area bounds")
+	}
+
+	return nil
+}
+
+func invalidStatus(s Status) error {
+	return invalidField(ErrInvalidStatus, "status", fmt.Sprintf("unknown status %q", s))
+}
+
+// IsValidPoint reports whether the point has WGS84 coordinates in range, NaN
+// and infinities excluded.
+func IsValidPoint(p Point) bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
 func IsValidEventType(t EventType) bool {
 	switch t {
-	case EventTripStart, EventTripEnd, EventLocation:
+	case EventTripStart, EventTripEnd, EventLocation, EventBattery:
 		return true
 
 	default:
 		return false
 	}
 }
+
+func isValidBattery(level int) bool {
+	return level >= 0 && level <= 100
+}
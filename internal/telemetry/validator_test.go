@@ -2,6 +2,7 @@ package telemetry_test
 
 import (
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/adrianpk/rida/internal/telemetry"
@@ -12,6 +13,8 @@ func TestDefaultValidator(t *testing.T) {
 	validID := uuid.New()
 	validArea := telemetry.Area{MinLat: 1, MaxLat: 2, MinLng: 3, MaxLng: 4}
 	invalidArea := telemetry.Area{MinLat: 2, MaxLat: 1, MinLng: 4, MaxLng: 3}
+	validBattery := 80
+	invalidBattery := 120
 
 	tests := []struct {
 		name    string
@@ -34,15 +37,27 @@ func TestDefaultValidator(t *testing.T) {
 		{
 			name:    "valid update scooter",
 			op:      telemetry.OpUpdateScooter,
-			data:    telemetry.Scooter{ID: validID},
+			data:    telemetry.ScooterPatch{ScooterID: validID, Battery: &validBattery},
 			wantErr: nil,
 		},
 		{
 			name:    "invalid update scooter (nil id)",
 			op:      telemetry.OpUpdateScooter,
-			data:    telemetry.Scooter{ID: uuid.Nil},
+			data:    telemetry.ScooterPatch{ScooterID: uuid.Nil, Battery: &validBattery},
 			wantErr: telemetry.ErrInvalidID,
 		},
+		{
+			name:    "invalid update scooter (empty patch)",
+			op:      telemetry.OpUpdateScooter,
+			data:    telemetry.ScooterPatch{ScooterID: validID},
+			wantErr: errors.New("invalid scooter: nothing to update"),
+		},
+		{
+			name:    "invalid update scooter (battery)",
+			op:      telemetry.OpUpdateScooter,
+			data:    telemetry.ScooterPatch{ScooterID: validID, Battery: &invalidBattery},
+			wantErr: errors.New("invalid scooter: invalid battery level"),
+		},
 		{
 			name:    "valid find scooters",
 			op:      telemetry.OpFindScooters,
@@ -53,19 +68,109 @@ func TestDefaultValidator(t *testing.T) {
 			name:    "invalid find scooters (area bounds)",
 			op:      telemetry.OpFindScooters,
 			data:    telemetry.Query{Area: invalidArea, Status: "free"},
-			wantErr: errors.New("invalid area bounds"),
+			wantErr: errors.New("invalid query: invalid area bounds"),
 		},
 		{
 			name:    "invalid find scooters (wrong type)",
 			op:      telemetry.OpFindScooters,
 			data:    123,
-			wantErr: errors.New("invalid query params"),
+			wantErr: telemetry.ErrInvalidQuery,
+		},
+		{
+			name:    "valid find scooters (any status)",
+			op:      telemetry.OpFindScooters,
+			data:    telemetry.Query{Area: validArea},
+			wantErr: nil,
+		},
+		{
+			name:    "invalid find scooters (unknown status)",
+			op:      telemetry.OpFindScooters,
+			data:    telemetry.Query{Area: validArea, Status: "broken"},
+			wantErr: errors.New(`invalid status: unknown status "broken"`),
+		},
+		{
+			name:    "invalid find scooters (min battery)",
+			op:      telemetry.OpFindScooters,
+			data:    telemetry.Query{Area: validArea, Status: "free", MinBattery: 101},
+			wantErr: errors.New("invalid query: invalid min battery"),
+		},
+		{
+			name:    "invalid find scooters (negative limit)",
+			op:      telemetry.OpFindScooters,
+			data:    telemetry.Query{Area: validArea, Limit: -1},
+			wantErr: errors.New("invalid query: invalid limit"),
+		},
+		{
+			name:    "valid scooter grid",
+			op:      telemetry.OpScooterGrid,
+			data:    telemetry.GridQuery{Area: validArea, CellSize: 0.1},
+			wantErr: nil,
+		},
+		{
+			name:    "invalid scooter grid (cell size)",
+			op:      telemetry.OpScooterGrid,
+			data:    telemetry.GridQuery{Area: validArea},
+			wantErr: errors.New("invalid query: cell size must be positive"),
+		},
+		{
+			name:    "invalid scooter grid (too many cells)",
+			op:      telemetry.OpScooterGrid,
+			data:    telemetry.GridQuery{Area: validArea, CellSize: 0.001},
+			wantErr: errors.New("invalid query: grid would have more than 10000 cells"),
+		},
+		{
+			name:    "invalid report event (battery level)",
+			op:      telemetry.OpReportEvent,
+			data:    telemetry.Event{ScooterID: validID, Type: telemetry.EventBattery, Battery: &invalidBattery},
+			wantErr: errors.New("invalid event: invalid battery level"),
+		},
+		{
+			name: "valid zone",
+			op:   telemetry.OpSaveZone,
+			data: telemetry.Zone{Name: "Old Port", Rule: telemetry.RuleSlowZone, MaxSpeed: 10, Polygon: []telemetry.Point{
+				{Lat: 1, Lng: 1}, {Lat: 1, Lng: 2}, {Lat: 2, Lng: 2}, {Lat: 1, Lng: 1},
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "invalid zone (closed ring with 2 vertices)",
+			op:   telemetry.OpSaveZone,
+			data: telemetry.Zone{Name: "Old Port", Rule: telemetry.RuleNoParking, Polygon: []telemetry.Point{
+				{Lat: 1, Lng: 1}, {Lat: 1, Lng: 2}, {Lat: 1, Lng: 1},
+			}},
+			wantErr: errors.New("invalid zone: polygon needs at least 3 vertices"),
+		},
+		{
+			name: "invalid zone (unknown rule)",
+			op:   telemetry.OpSaveZone,
+			data: telemetry.Zone{Name: "Old Port", Rule: "no_fun", Polygon: []telemetry.Point{
+				{Lat: 1, Lng: 1}, {Lat: 1, Lng: 2}, {Lat: 2, Lng: 2},
+			}},
+			wantErr: errors.New(`invalid zone: unknown rule "no_fun"`),
+		},
+		{
+			name:    "invalid report event (location out of range)",
+			op:      telemetry.OpReportEvent,
+			data:    telemetry.Event{ScooterID: validID, Type: telemetry.EventLocation, Lat: 91, Lng: 3},
+			wantErr: errors.New("invalid event: invalid coordinates"),
+		},
+		{
+			name:    "invalid report event (NaN location)",
+			op:      telemetry.OpReportEvent,
+			data:    telemetry.Event{ScooterID: validID, Type: telemetry.EventLocation, Lat: 1, Lng: math.NaN()},
+			wantErr: errors.New("invalid event: invalid coordinates"),
+		},
+		{
+			name:    "invalid import (NaN coordinates)",
+			op:      telemetry.OpImportScooter,
+			data:    telemetry.Scooter{ID: validID, Status: telemetry.StatusFree, Lat: math.NaN(), Lng: 3, Battery: validBattery},
+			wantErr: errors.New("invalid scooter: invalid coordinates"),
 		},
 		{
 			name:    "invalid report event (bad type)",
 			op:      telemetry.OpReportEvent,
 			data:    telemetry.Event{ID: validID, ScooterID: validID, Type: "bad_type"},
-			wantErr: errors.New("invalid event type"),
+			wantErr: errors.New("invalid event: invalid event type"),
 		},
 	}
 
@@ -83,6 +188,24 @@ func TestDefaultValidator(t *testing.T) {
 	}
 }
 
+func TestIsValidStatus(t *testing.T) {
+	for _, s := range []telemetry.Status{
+		telemetry.StatusFree, telemetry.StatusOccupied, telemetry.StatusReserved,
+		telemetry.StatusMaintenance, telemetry.StatusOffline, telemetry.StatusLowBattery,
+		telemetry.StatusDecommissioned,
+	} {
+		if !telemetry.IsValidStatus(s) {
+			t.Errorf("IsValidStatus(%q) = false, want true", s)
+		}
+	}
+
+	for _, s := range []telemetry.Status{"", "broken", "FREE"} {
+		if telemetry.IsValidStatus(s) {
+			t.Errorf("IsValidStatus(%q) = true, want false", s)
+		}
+	}
+}
+
 func TestIsValidEventType(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -92,6 +215,7 @@ func TestIsValidEventType(t *testing.T) {
 		{"trip_start valid", telemetry.EventTripStart, true},
 		{"trip_end valid", telemetry.EventTripEnd, true},
 		{"location valid", telemetry.EventLocation, true},
+		{"battery valid", telemetry.EventBattery, true},
 		{"invalid type", "bad_type", false},
 	}
 	for _, tt := range tests {
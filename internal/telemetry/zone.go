@@ -0,0 +1,138 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ZoneRule string
+
+const (
+	// RuleNoParking forbids ending a trip inside the zone.
+	RuleNoParking ZoneRule = "no_parking"
+	// RuleSlowZone limits riding speed inside the zone to MaxSpeed.
+	RuleSlowZone ZoneRule = "slow_zone"
+	// RuleOutOfServiceArea forbids riding or parking inside the zone.
+	RuleOutOfServiceArea ZoneRule = "out_of_service_area"
+)
+
+func IsValidZoneRule(r ZoneRule) bool {
+	switch r {
+	case RuleNoParking, RuleSlowZone, RuleOutOfServiceArea:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// Zone is a named polygon geofence with a rule that location and trip_end
+// events are checked against.
+type Zone struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Rule      ZoneRule  `json:"rule"`
+	Polygon   []Point   `json:"polygon"`            // outer ring, closing vertex optional
+	MaxSpeed  float64   `json:"maxSpeed,omitempty"` // km/h, slow zones only
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (z *Zone) GenID() {
+	if z.ID == uuid.Nil {
+		z.ID = uuid.New()
+	}
+}
+
+func (z *Zone) GenCreateVals() {
+	z.GenID()
+	z.CreatedAt = time.Now()
+	z.UpdatedAt = z.CreatedAt
+}
+
+func (z *Zone) AuditUpdate() {
+	z.UpdatedAt = time.Now()
+}
+
+// Contains reports whether the point lies inside the zone polygon using the
+// even-odd ray casting rule on the lat/lng plane, which is accurate enough
+// for city-sized zones.
+func (z *Zone) Contains(p Point) bool {
+	inside := false
+	n := len(z.Polygon)
+
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := z.Polygon[i], z.Polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Violated reports whether an event of the given type, happening inside the
+// zone at the given speed (km/h), breaks the zone rule.
+func (z *Zone) Violated(t EventType, speed float64) bool {
+	switch z.Rule {
+	case RuleNoParking:
+		return t == EventTripEnd
+	case RuleOutOfServiceArea:
+		return t == EventLocation || t == EventTripEnd
+	case RuleSlowZone:
+		return t == EventLocation && z.MaxSpeed > 0 && speed > z.MaxSpeed
+	default:
+		return false
+	}
+}
+
+// Violation records an event that broke a zone rule.
+type Violation struct {
+	ID         uuid.UUID  `json:"id"`
+	ZoneID     uuid.UUID  `json:"zoneId"`
+	ZoneName   string     `json:"zoneName"`
+	Rule       ZoneRule   `json:"rule"`
+	ScooterID  uuid.UUID  `json:"scooterId"`
+	TripID     *uuid.UUID `json:"tripId,omitempty"`
+	EventID    uuid.UUID  `json:"eventId"`
+	Lat        float64    `json:"lat"`
+	Lng        float64    `json:"lng"`
+	Speed      float64    `json:"speed"` // km/h
+	OccurredAt time.Time  `json:"occurredAt"`
+}
+
+func (v *Violation) GenID() {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+}
+
+type ViolationQuery struct {
+	ScooterID uuid.UUID
+	TripID    uuid.UUID
+}
+
+// Match reports whether the violation satisfies the query filters.
+func (q ViolationQuery) Match(v Violation) bool {
+	if q.ScooterID != uuid.Nil && v.ScooterID != q.ScooterID {
+		return false
+	}
+
+	if q.TripID != uuid.Nil && (v.TripID == nil || *v.TripID != q.TripID) {
+		return false
+	}
+
+	return true
+}
+
+// speed returns the speed in km/h needed to move between two points in d.
+func speed(from, to Point, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+
+	return Distance(from, to) / d.Seconds() * 3.6
+}
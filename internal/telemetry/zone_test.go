@@ -0,0 +1,66 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/adrianpk/rida/internal/telemetry"
+)
+
+func TestZoneContains(t *testing.T) {
+	// L-shaped zone to exercise the concave corner.
+	z := telemetry.Zone{
+		Polygon: []telemetry.Point{
+			{Lat: 0, Lng: 0},
+			{Lat: 0, Lng: 2},
+			{Lat: 1, Lng: 2},
+			{Lat: 1, Lng: 1},
+			{Lat: 2, Lng: 1},
+			{Lat: 2, Lng: 0},
+		},
+	}
+
+	tests := []struct {
+		name string
+		p    telemetry.Point
+		want bool
+	}{
+		{"inside lower arm", telemetry.Point{Lat: 0.5, Lng: 1.5}, true},
+		{"inside upper arm", telemetry.Point{Lat: 1.5, Lng: 0.5}, true},
+		{"concave notch", telemetry.Point{Lat: 1.5, Lng: 1.5}, false},
+		{"outside", telemetry.Point{Lat: -1, Lng: 0.5}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := z.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZoneViolated(t *testing.T) {
+	tests := []struct {
+		name  string
+		zone  telemetry.Zone
+		event telemetry.EventType
+		speed float64
+		want  bool
+	}{
+		{"parking in no parking zone", telemetry.Zone{Rule: telemetry.RuleNoParking}, telemetry.EventTripEnd, 0, true},
+		{"riding through no parking zone", telemetry.Zone{Rule: telemetry.RuleNoParking}, telemetry.EventLocation, 20, false},
+		{"riding out of service area", telemetry.Zone{Rule: telemetry.RuleOutOfServiceArea}, telemetry.EventLocation, 10, true},
+		{"parking out of service area", telemetry.Zone{Rule: telemetry.RuleOutOfServiceArea}, telemetry.EventTripEnd, 0, true},
+		{"speeding in slow zone", telemetry.Zone{Rule: telemetry.RuleSlowZone, MaxSpeed: 10}, telemetry.EventLocation, 15, true},
+		{"under limit in slow zone", telemetry.Zone{Rule: telemetry.RuleSlowZone, MaxSpeed: 10}, telemetry.EventLocation, 8, false},
+		{"parking in slow zone", telemetry.Zone{Rule: telemetry.RuleSlowZone, MaxSpeed: 10}, telemetry.EventTripEnd, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.zone.Violated(tt.event, tt.speed); got != tt.want {
+				t.Errorf("Violated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // tariff timezones must resolve on images without zoneinfo
 
 	"github.com/adrianpk/rida/internal/cfg"
 	"github.com/adrianpk/rida/internal/client"
 	"github.com/adrianpk/rida/internal/repo/pg"
 	"github.com/adrianpk/rida/internal/telemetry"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -25,19 +29,49 @@ func main() {
 	db := pg.NewDB(config)
 	repo := pg.NewTelemetryRepo(db)
 
+	if args := flag.Args(); len(args) > 0 {
+		err := runCommand(config, db, repo, args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	err := startDeps(db, repo)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	service := telemetry.NewService(repo)
+	opts := []telemetry.Option{
+		telemetry.WithLowBatteryThreshold(config.LowBatteryThreshold),
+		telemetry.WithReservationTTL(config.ReservationTTL),
+		telemetry.WithDedupWindow(config.DedupWindow),
+		telemetry.WithMaxClockSkew(config.MaxClockSkew),
+	}
+
+	if config.TariffsFile != "" {
+		tariffs, err := telemetry.LoadTariffs(config.TariffsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, telemetry.WithPricer(tariffs))
+	}
+
+	service := telemetry.NewService(repo, opts...)
 	handler := telemetry.NewHandler(service)
-	router := telemetry.NewRouter(handler, config.APIKey)
+	var router http.Handler = telemetry.NewRouter(handler, []string{config.APIKey}, config.OperatorAPIKey)
+	if config.Dev {
+		router = telemetry.ResponseValidationMiddleware(router)
+	}
+	grpcServer := telemetry.NewGRPCServer(service, []string{config.APIKey}, config.OperatorAPIKey)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	go telemetry.SweepReservations(ctx, service, telemetry.ReservationSweepInterval)
+	go telemetry.SweepProcessedEvents(ctx, service, telemetry.DedupSweepInterval)
 	go startClients(ctx, config)
+	go startGRPCServer(grpcServer, config)
 	startServer(router, config)
 }
 
@@ -59,6 +93,16 @@ func startServer(router http.Handler, config *cfg.Config) {
 	log.Fatal(http.ListenAndServe(config.HTTPPort, router))
 }
 
+func startGRPCServer(server *grpc.Server, config *cfg.Config) {
+	lis, err := net.Listen("tcp", config.GRPCPort)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("%s gRPC running on %s", AppName, config.GRPCPort)
+	log.Fatal(server.Serve(lis))
+}
+
 func startClients(ctx context.Context, config *cfg.Config) {
 	manager := client.NewClientManager(config)
 	manager.Start(ctx)